@@ -0,0 +1,168 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	archiveCmd = &cobra.Command{
+		Use:   "archive",
+		Short: "Edit archives without a Cluster",
+		Long: `These commands remove, reorder, merge and split the Releases in
+	 archives, operating purely on files. To add Releases, see import.`,
+	}
+	archiveRmCmd = &cobra.Command{
+		Use:   "rm NAME...",
+		Short: "Remove Releases from File",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk archive rm called")
+			archiveRm(args)
+		},
+	}
+	archiveMergeCmd = &cobra.Command{
+		Use:   "merge FIRST SECOND",
+		Short: "Merge the Releases in two archives into a new archive",
+		Long: `This command will write the Releases in the first archive, followed
+	 by those in the second, to the archive given by -o. Releases in both are
+	 resolved by --conflict.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk archive merge called")
+			archiveMerge(args[0], args[1])
+		},
+	}
+	archiveSplitCmd = &cobra.Command{
+		Use:   "split",
+		Short: "Split File into an archive per namespace",
+		Long: `This command will write the Releases in File to an archive per
+	 namespace alongside it, e.g. helm-releases-kube-system.tar.gz.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk archive split called")
+			archiveSplit()
+		},
+	}
+	archiveReorderCmd = &cobra.Command{
+		Use:   "reorder",
+		Short: "Reorder the Releases in File",
+		Long: `This command will reorder the Releases in File by the order in
+	 --order-file, as in orderPref.yaml. Releases not named there follow, in
+	 their existing order.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk archive reorder called")
+			archiveReorder()
+		},
+	}
+	mergeOutput   string
+	mergeConflict string
+	splitBy       string
+	orderFile     string
+)
+
+func init() {
+	archiveMergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "",
+		"Archive to write the merged Releases to")
+	archiveMergeCmd.Flags().StringVar(&mergeConflict, "conflict",
+		utils.ConflictFail, "How to resolve a Release in both archives: fail,"+
+			" first to keep the first archive's, or second to keep the second's")
+	archiveSplitCmd.Flags().StringVar(&splitBy, "by", "namespace",
+		"What to split by: namespace")
+	archiveReorderCmd.Flags().StringVar(&orderFile, "order-file", "",
+		"Config file holding the new order, in the same format as orderPref.yaml")
+	archiveCmd.AddCommand(archiveRmCmd, archiveMergeCmd, archiveSplitCmd,
+		archiveReorderCmd)
+	rootCmd.AddCommand(archiveCmd)
+}
+
+//mustReadReleases decodes the Releases and metadata in the archive at the
+//location, panicking if there's no archive there
+func mustReadReleases(location string) ([]*release.Release,
+	*utils.ArchiveMetadata) {
+	releases, metadata, found := readReleases(location)
+	if !found {
+		panic("Archive: " + location + " not found")
+	}
+	return releases, metadata
+}
+
+//archiveRm removes the named Releases from File
+func archiveRm(names []string) {
+	location := archiveFilename()
+	releases, metadata := mustReadReleases(location)
+	for _, name := range names {
+		i := utils.ReleaseIndex(name, releases)
+		if i < 0 {
+			panic("Release: " + name + " not found in " + location)
+		}
+		releases = append(releases[:i], releases[i+1:]...)
+	}
+	writeReleases(location, releases, metadata)
+	log.Println("Removed", len(names), "Helm Releases from", location)
+}
+
+//archiveMerge writes the merged Releases of the two archives to --output, with
+//the first's metadata, less any source the archives don't share
+func archiveMerge(first, second string) {
+	if mergeOutput == "" {
+		panic("-o, --output is required")
+	}
+	firstReleases, firstMetadata := mustReadReleases(first)
+	secondReleases, secondMetadata := mustReadReleases(second)
+	merged, err := utils.MergeReleases(firstReleases, secondReleases,
+		mergeConflict)
+	utils.PanicCheck(err)
+	writeReleases(mergeOutput, merged, utils.MergedMetadata(firstMetadata,
+		secondMetadata))
+	log.Println("Wrote", len(merged), "Helm Releases to", mergeOutput)
+}
+
+//archiveSplit writes the Releases in File to an archive per namespace
+func archiveSplit() {
+	if splitBy != "namespace" {
+		panic("Unknown split '" + splitBy + "', must be namespace")
+	}
+	if streaming() {
+		panic("archive split can't write to stdout, use --file to name the" +
+			" archive")
+	}
+	location := archiveFilename()
+	releases, metadata := mustReadReleases(location)
+	namespaces, groups := utils.GroupByNamespace(releases)
+	for _, namespace := range namespaces {
+		output := utils.SuffixedArchiveName(location, namespace)
+		writeReleases(output, groups[namespace], metadata)
+		log.Println("Wrote", len(groups[namespace]), "Helm Releases to", output)
+	}
+}
+
+//archiveReorder reorders the Releases in File by --order-file
+func archiveReorder() {
+	if orderFile == "" {
+		panic("--order-file is required")
+	}
+	order, err := utils.OrderFile(orderFile)
+	utils.PanicCheck(err)
+	location := archiveFilename()
+	releases, metadata := mustReadReleases(location)
+	releases = orderReleases(releases, order)
+	writeReleases(location, releases, metadata)
+	logReleases(releases, "Helm Releases reordered in "+location+":")
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"strings"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//decodeArchive decodes the Releases and metadata in the archive
+func decodeArchive(archive io.Reader) (releases []*release.Release,
+	metadata *utils.ArchiveMetadata) {
+	dat, metadata, err := utils.ReadArchive(archive)
+	utils.PanicCheck(err)
+	for _, splitString := range strings.Split(string(dat), ",") {
+		if splitString == "" {
+			continue
+		}
+		release, err := utils.DecodeRelease(splitString)
+		utils.PanicCheck(err)
+		releases = append(releases, release)
+	}
+	return
+}
+
+//readReleases decodes the Releases and metadata in the archive at the
+//location, returning found as false if there's no archive there
+func readReleases(location string) (releases []*release.Release,
+	metadata *utils.ArchiveMetadata, found bool) {
+	store, name, err := utils.NewStore(location)
+	utils.PanicCheck(err)
+	archive, err := store.Get(name)
+	if utils.IsNotFound(err) {
+		return nil, nil, false
+	}
+	utils.PanicCheck(err)
+	defer archive.Close()
+	releases, metadata = decodeArchive(archive)
+	return releases, metadata, true
+}
+
+//writeReleases writes the Releases to an archive at the location, in the same
+//format as save. The metadata of the archive they were read from is kept,
+//recording this helm-bulk as having written it.
+func writeReleases(location string, releases []*release.Release,
+	metadata *utils.ArchiveMetadata) {
+	edited := utils.ArchiveMetadata{}
+	if metadata != nil {
+		edited = *metadata
+	}
+	edited.HelmBulkVersion = Version
+	store, name, err := utils.NewStore(location)
+	utils.PanicCheck(err)
+	utils.PanicCheck(store.Put(name,
+		archiveReleases(encodeReleases(releases), &edited)))
+}
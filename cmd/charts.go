@@ -0,0 +1,77 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	chartOverrides []string
+	chartDir       string
+)
+
+func init() {
+	loadCmd.Flags().StringArrayVar(&chartOverrides, "chart-override", nil,
+		"Replace a Release's saved chart with a local chart directory or .tgz,"+
+			" as release=path, keeping the saved values. Can be repeated")
+	loadCmd.Flags().StringVar(&chartDir, "chart-dir", "",
+		"Replace each Release's saved chart with one found in this directory,"+
+			" named after the Release or its chart, keeping the saved values")
+}
+
+//substituteCharts replaces the saved charts of the provided Releases with any
+//given by --chart-override, or found in --chart-dir
+func substituteCharts(releases []*release.Release) {
+	overrides, err := utils.ParseChartOverrides(chartOverrides)
+	utils.PanicCheck(err)
+	for _, release := range releases {
+		path, ok := overrides[release.GetName()]
+		if !ok && chartDir != "" {
+			path = utils.FindChart(chartDir, release)
+		}
+		if path != "" {
+			substituteChart(release, path)
+		}
+	}
+	for name := range overrides {
+		if utils.ContainsRelease(&release.Release{Name: name}, releases) {
+			continue
+		}
+		log.Println("WARNING: chart override given for Release:", name,
+			"which isn't in the File")
+	}
+}
+
+//substituteChart replaces the saved chart of the Release with the chart at the
+//path, warning if the chart's name differs
+func substituteChart(release *release.Release, path string) {
+	chart, err := chartutil.Load(path)
+	utils.PanicCheck(err)
+	saved := release.GetChart().GetMetadata()
+	loaded := chart.GetMetadata()
+	if saved.GetName() != loaded.GetName() {
+		log.Println("WARNING: Release:", release.GetName(), "was saved with chart",
+			saved.GetName(), "but is being loaded with chart", loaded.GetName())
+	}
+	log.Println("Release:", release.GetName(), "chart", saved.GetName()+"-"+
+		saved.GetVersion(), "replaced with", loaded.GetName()+"-"+
+		loaded.GetVersion(), "from", path)
+	release.Chart = chart
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/ovotech/helm-bulk/utils"
+)
+
+var allowCrossCluster bool
+var clusterContext string
+
+func init() {
+	loadCmd.Flags().BoolVar(&allowCrossCluster, "allow-cross-cluster", false,
+		"Load File into a Cluster other than the one it was saved from")
+	rootCmd.PersistentFlags().StringVar(&clusterContext, "cluster-context", "",
+		"kubeconfig context of the Cluster Tiller runs in, passed to kubectl."+
+			" Set it to helm's --kube-context, which helm doesn't pass to"+
+			" plugins. Defaults to kubectl's current context")
+}
+
+//newCluster returns the Cluster of --cluster-context
+func newCluster() utils.Cluster {
+	return utils.NewCluster(clusterContext)
+}
+
+//checkCluster logs the Cluster File was saved from and the target Cluster,
+//panicking if they differ, or if kubectl can't identify the target Cluster,
+//unless --allow-cross-cluster is set. Files that don't record their source
+//Cluster aren't checked, so don't need kubectl.
+func checkCluster(metadata *utils.ArchiveMetadata, cluster utils.Cluster) {
+	logSource(metadata)
+	if metadata.GetServer() == "" {
+		return
+	}
+	context, err := cluster.Context()
+	if err != nil {
+		if !allowCrossCluster {
+			panic("target Cluster can't be identified, kubectl config view" +
+				" failed: " + err.Error() + ", set --allow-cross-cluster to" +
+				" load File anyway")
+		}
+		log.Println("WARNING: target Cluster not checked, kubectl config view"+
+			" failed:", err)
+		return
+	}
+	log.Println("Loading into context", context.Name, "("+context.Server+")")
+	if !utils.CrossCluster(metadata, context) {
+		return
+	}
+	if !allowCrossCluster {
+		panic("File was saved from " + metadata.Server + ", not the target " +
+			context.Server + ", set --allow-cross-cluster to load it anyway")
+	}
+	log.Println("WARNING: loading File saved from", metadata.Server,
+		"into", context.Server)
+}
+
+//logSource logs where, when and by whom File was saved, if it was recorded
+func logSource(metadata *utils.ArchiveMetadata) {
+	if metadata.GetServer() == "" {
+		log.Println("Source Cluster unknown")
+		return
+	}
+	log.Println("Saved from context", metadata.KubeContext,
+		"("+metadata.Server+"), Tiller namespace",
+		orUnknown(metadata.TillerNamespace),
+		"by", orUnknown(metadata.User), "at", metadata.SavedAt)
+}
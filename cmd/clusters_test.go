@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ovotech/helm-bulk/utils"
+)
+
+//unknownCluster is a fakeCluster that kubectl can't identify
+type unknownCluster struct {
+	fakeCluster
+}
+
+func (unknownCluster) Context() (utils.ClusterContext, error) {
+	return utils.ClusterContext{}, errors.New("kubectl failed")
+}
+
+func TestCheckCluster(t *testing.T) {
+	defer func() { allowCrossCluster = false }()
+	prod := &utils.ArchiveMetadata{KubeContext: "prod",
+		Server: "https://prod:6443"}
+	if !panics(func() { checkCluster(prod, fakeCluster{}) }) {
+		t.Error("Cluster check loaded an archive from another Cluster")
+	}
+	staging := &utils.ArchiveMetadata{KubeContext: "staging",
+		Server: "https://staging:6443"}
+	if panics(func() { checkCluster(staging, fakeCluster{}) }) {
+		t.Error("Cluster check refused an archive from the same Cluster")
+	}
+	if panics(func() { checkCluster(nil, fakeCluster{}) }) {
+		t.Error("Cluster check refused an archive without metadata")
+	}
+	if !panics(func() { checkCluster(staging, unknownCluster{}) }) {
+		t.Error("Cluster check loaded into a Cluster it couldn't identify")
+	}
+	allowCrossCluster = true
+	if panics(func() { checkCluster(prod, fakeCluster{}) }) {
+		t.Error("Cluster check refused another Cluster with --allow-cross-cluster")
+	}
+	if panics(func() { checkCluster(staging, unknownCluster{}) }) {
+		t.Error("Cluster check refused an unidentified Cluster with" +
+			" --allow-cross-cluster")
+	}
+}
+
+func TestCheckClusterWithoutSource(t *testing.T) {
+	for _, metadata := range []*utils.ArchiveMetadata{nil, {}} {
+		if panics(func() { checkCluster(metadata, unknownCluster{}) }) {
+			t.Errorf("Cluster check refused an archive without a source (%+v)"+
+				" when kubectl failed", metadata)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//purge deletes the provided releases, once confirmed and saved to a safety
+//archive. Failures are logged and reported, and don't stop the remaining
+//Releases from being purged.
+func purge(releasesToPurge []*release.Release, client helm.Interface) {
+	releasesToPurge = notProcessedPreviously(releasesToPurge)
+	if !dryRun && len(releasesToPurge) > 0 {
+		var buffer bytes.Buffer
+		buffer.WriteString("About to purge existing releases:")
+		buffer.WriteString("\n\n")
+		addReleasesToBuffer(releasesToPurge, &buffer)
+		log.Println(buffer.String())
+		if !confirmPurge() {
+			panic("Purge not confirmed, aborting load")
+		}
+		writeSafetyArchive(releasesToPurge, client)
+		failures := map[string]error{}
+		for _, release := range releasesToPurge {
+			releaseName := release.GetName()
+			err := purgeRelease(releaseName, client)
+			recordProgress(releaseName, "purge", err)
+			if err != nil {
+				log.Println("purge of Release:", releaseName, "failed:", err.Error())
+				failures[releaseName] = err
+			}
+		}
+		logPurgeReport(releasesToPurge, failures)
+	}
+}
+
+//notProcessedPreviously filters out Releases that were purged or loaded by
+//the load being resumed
+func notProcessedPreviously(releases []*release.Release) (filtered []*release.Release) {
+	for _, release := range releases {
+		if processedPreviously(release.GetName()) {
+			log.Println("not purging Release:", release.GetName(),
+				"as it was processed in the load being resumed")
+			continue
+		}
+		filtered = append(filtered, release)
+	}
+	return
+}
+
+//confirmPurge returns whether the purge has been confirmed, either with --yes
+//or by answering a prompt on stdin
+func confirmPurge() bool {
+	if yes {
+		return true
+	}
+	if streaming() {
+		panic("--yes is required to purge Releases when the archive is read" +
+			" from stdin")
+	}
+	fmt.Fprint(os.Stderr, "Purging deletes these Releases and their history."+
+		" Type 'yes' to continue: ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
+
+//writeSafetyArchive saves the Releases about to be purged to an archive
+//alongside the one being loaded, e.g.
+//helm-releases-pre-delete-20190509T153000Z.tar.gz, so they can be restored.
+//When streaming, it's written to the working directory instead.
+func writeSafetyArchive(releases []*release.Release, client helm.Interface) {
+	store, name := archiveStore()
+	if streaming() {
+		var err error
+		store, name, err = utils.NewStore(filePrefix + ".tar.gz")
+		utils.PanicCheck(err)
+	}
+	name = utils.TimestampedArchiveName(
+		strings.TrimSuffix(name, ".tar.gz")+"-pre-delete.tar.gz", time.Now())
+	utils.PanicCheck(store.Put(name, archiveReleases(encodeReleases(releases),
+		savedMetadata(client))))
+	log.Println("Saved Releases to be purged to safety archive:", name)
+}
+
+//logPurgeReport logs the outcome of purging each Release
+func logPurgeReport(releases []*release.Release, failures map[string]error) {
+	var buffer bytes.Buffer
+	addHeaderToBuffer("Purge report:", &buffer)
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "    RELEASE\tOUTCOME")
+	for _, release := range releases {
+		outcome := "purged"
+		if err := failures[release.GetName()]; err != nil {
+			outcome = "FAILED: " + err.Error()
+		}
+		fmt.Fprintf(w, "    %s\t%s\n", release.GetName(), outcome)
+	}
+	w.Flush()
+	log.Println(buffer.String())
+}
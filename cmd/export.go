@@ -0,0 +1,128 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export Releases from File to declarative config",
+		Long: `This command will decode the Releases in File and write them out
+	 in the given format, either as a helmfile.yaml with values files and
+	 unpacked charts, or as plain Kubernetes manifests.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk export called")
+			export(Releases())
+		},
+	}
+	exportFormat string
+	exportDir    string
+	exportForce  bool
+)
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", utils.ExportHelmfile,
+		"Format to export to: helmfile, or manifests")
+	exportCmd.Flags().StringVar(&exportDir, "out", "",
+		"Directory to export to (required)")
+	exportCmd.Flags().BoolVar(&exportForce, "force", false,
+		"Replace chart and Release directories already in --out")
+	exportCmd.Flags().BoolVar(&latest, "latest", false,
+		"Export the newest archive written with 'save --timestamp'")
+	rootCmd.AddCommand(exportCmd)
+}
+
+//export writes the Releases to the --out directory, in the --format
+func export(releases []*release.Release) {
+	if exportDir == "" {
+		panic("--out is required")
+	}
+	utils.PanicCheck(os.MkdirAll(exportDir, 0755))
+	switch exportFormat {
+	case utils.ExportHelmfile:
+		exportHelmfile(releases)
+	case utils.ExportManifests:
+		exportManifests(releases)
+	default:
+		panic("Unknown export format '" + exportFormat + "', must be " +
+			utils.ExportHelmfile + " or " + utils.ExportManifests)
+	}
+	log.Println(len(releases), "Releases exported to", exportDir)
+}
+
+//exportHelmfile writes a helmfile.yaml for the Releases, along with their
+//values files and unpacked charts. Unless --force is set, it panics before
+//writing anything if any of them already exist.
+func exportHelmfile(releases []*release.Release) {
+	paths := []string{"helmfile.yaml"}
+	for _, release := range releases {
+		paths = append(paths, utils.HelmfileChartDir(release))
+		if release.GetConfig().GetRaw() != "" {
+			paths = append(paths, utils.HelmfileValuesFile(release))
+		}
+	}
+	for _, name := range paths {
+		utils.PanicCheck(utils.ClearPath(
+			filepath.Join(exportDir, filepath.FromSlash(name)), exportForce))
+	}
+	for _, release := range releases {
+		chartDir, err := utils.WriteChartDir(release.GetChart(),
+			filepath.Join(exportDir, utils.HelmfileChartDir(release)),
+			exportForce)
+		utils.PanicCheck(err)
+		log.Println("Release:", release.GetName(), "chart written to", chartDir)
+		if raw := release.GetConfig().GetRaw(); raw != "" {
+			writeExportFile(utils.HelmfileValuesFile(release), []byte(raw))
+		}
+	}
+	helmfile, err := utils.NewHelmfile(releases).Marshal()
+	utils.PanicCheck(err)
+	writeExportFile("helmfile.yaml", helmfile)
+}
+
+//writeExportFile writes the data to the file, relative to the --out directory
+func writeExportFile(name string, data []byte) {
+	file := filepath.Join(exportDir, filepath.FromSlash(name))
+	utils.PanicCheck(os.MkdirAll(filepath.Dir(file), 0755))
+	utils.PanicCheck(ioutil.WriteFile(file, data, 0644))
+}
+
+//exportManifests writes a directory per Release, holding a file per resource
+//in its manifest, and a file per hook in a hooks directory
+func exportManifests(releases []*release.Release) {
+	for _, release := range releases {
+		files, err := utils.ManifestFiles(release)
+		utils.PanicCheck(err)
+		dir := filepath.Join(exportDir, release.GetName())
+		utils.PanicCheck(utils.ClearPath(dir, exportForce))
+		utils.PanicCheck(os.MkdirAll(dir, 0755))
+		for name, manifest := range files {
+			writeExportFile(path.Join(release.GetName(), name), []byte(manifest))
+		}
+		log.Println("Release:", release.GetName(), "written as", len(files),
+			"manifests")
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestExportHelmfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-bulk-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	exportDir = dir
+	defer func() { exportDir, exportForce = "", false }()
+	releases := []*release.Release{{
+		Name:      "app",
+		Namespace: "web",
+		Config:    &chart.Config{Raw: "replicaCount: 2\n"},
+		Chart: &chart.Chart{
+			Metadata:  &chart.Metadata{Name: "nginx", Version: "0.1.0"},
+			Templates: []*chart.Template{{Name: "templates/svc.yaml"}},
+		},
+	}}
+	exportHelmfile(releases)
+	edited := map[string]string{
+		"helmfile.yaml":   "# edited\n",
+		"values/app.yaml": "replicaCount: 3\n",
+	}
+	for name, data := range edited {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)),
+			[]byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !panics(func() { exportHelmfile(releases) }) {
+		t.Error("Existing helmfile and values were replaced without --force")
+	}
+	for name, data := range edited {
+		got, _ := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if string(got) != data {
+			t.Errorf("File %s was changed without --force, got: %s, want: %s.",
+				name, got, data)
+		}
+	}
+	exportForce = true
+	exportHelmfile(releases)
+	values, _ := ioutil.ReadFile(filepath.Join(dir, "values", "app.yaml"))
+	if string(values) != "replicaCount: 2\n" {
+		t.Errorf("Values were incorrect with --force, got: %s, want: %s.",
+			values, "replicaCount: 2\n")
+	}
+}
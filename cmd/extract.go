@@ -0,0 +1,112 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	extractCmd = &cobra.Command{
+		Use:   "extract",
+		Short: "Extract a Release's chart and values from File",
+		Long: `This command will write the chart saved with a Release in File to
+	 disk, as a chart directory or .tgz, along with the Release's values as
+	 values.yaml, so it can be installed by hand with plain Helm.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk extract called")
+			extract(namedRelease(extractRelease, Releases()))
+		},
+	}
+	extractRelease string
+	extractDir     string
+	extractPackage bool
+	extractForce   bool
+)
+
+func init() {
+	extractCmd.Flags().StringVar(&extractRelease, "release", "",
+		"Name of the Release to extract")
+	extractCmd.Flags().StringVar(&extractDir, "out", "",
+		"Directory to extract to (required)")
+	extractCmd.Flags().BoolVar(&extractForce, "force", false,
+		"Replace a chart and values.yaml already in --out")
+	extractCmd.Flags().BoolVar(&extractPackage, "package", false,
+		"Write the chart as a .tgz, rather than a chart directory")
+	extractCmd.Flags().BoolVar(&latest, "latest", false,
+		"Extract from the newest archive written with 'save --timestamp'")
+	rootCmd.AddCommand(extractCmd)
+}
+
+//namedRelease returns the Release with the provided name, panicking if there
+//isn't one
+func namedRelease(name string, releases []*release.Release) *release.Release {
+	if name == "" {
+		panic("--release is required")
+	}
+	found := utils.MatchRelease(&release.Release{Name: name}, releases,
+		utils.IdentityName)
+	if found == nil {
+		panic("Release: " + name + " not found in File")
+	}
+	return found
+}
+
+//extract writes the Release's chart and values to the --out directory. A chart
+//or values.yaml already there is only replaced with --force.
+func extract(release *release.Release) {
+	if extractDir == "" {
+		panic("--out is required")
+	}
+	if release.GetChart().GetMetadata() == nil {
+		panic("Release: " + release.GetName() + " has no saved chart")
+	}
+	utils.PanicCheck(os.MkdirAll(extractDir, 0755))
+	valuesPath := filepath.Join(extractDir, chartutil.ValuesfileName)
+	utils.PanicCheck(utils.ClearPath(valuesPath, extractForce))
+	chartPath := extractChart(release.GetChart())
+	utils.PanicCheck(ioutil.WriteFile(valuesPath,
+		[]byte(release.GetConfig().GetRaw()), 0644))
+	log.Println("Release:", release.GetName(), "chart written to", chartPath,
+		"and values to", valuesPath)
+	log.Println("To reinstall: helm install", chartPath, "--name",
+		release.GetName(), "--namespace", release.GetNamespace(), "-f",
+		valuesPath)
+}
+
+//extractChart writes the chart to the --out directory as a chart directory, or
+//a .tgz with --package, returning its path
+func extractChart(c *chart.Chart) string {
+	if !extractPackage {
+		chartPath, err := utils.WriteChartDir(c, extractDir, extractForce)
+		utils.PanicCheck(err)
+		return chartPath
+	}
+	metadata := c.GetMetadata()
+	utils.PanicCheck(utils.ClearPath(filepath.Join(extractDir,
+		metadata.GetName()+"-"+metadata.GetVersion()+".tgz"), extractForce))
+	chartPath, err := chartutil.Save(c, extractDir)
+	utils.PanicCheck(err)
+	return chartPath
+}
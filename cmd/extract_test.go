@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-bulk-extract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	extractDir = dir
+	defer func() { extractDir, extractForce = "", false }()
+	releases := []*release.Release{{
+		Name:   "app",
+		Config: &chart.Config{Raw: "replicaCount: 2\n"},
+		Chart: &chart.Chart{
+			Metadata:  &chart.Metadata{Name: "nginx", Version: "0.1.0"},
+			Templates: []*chart.Template{{Name: "templates/svc.yaml"}},
+		},
+	}}
+	extract(namedRelease("app", releases))
+	if !panics(func() { extract(releases[0]) }) {
+		t.Error("Existing chart and values were replaced without --force")
+	}
+	extractForce = true
+	extract(releases[0])
+	for _, name := range []string{"values.yaml", "nginx/Chart.yaml",
+		"nginx/templates/svc.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("File %s not extracted: %v", name, err)
+		}
+	}
+	values, _ := ioutil.ReadFile(filepath.Join(dir, "values.yaml"))
+	if string(values) != "replicaCount: 2\n" {
+		t.Errorf("Values were incorrect, got: %s, want: %s.", values,
+			"replicaCount: 2\n")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	importCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Import Releases from charts and values files to File",
+		Long: `This command will build Releases from local charts and values files,
+	 without a Cluster, and add them to File in the same format as save, so
+	 they can then be loaded. Either a single Release is given by flags, or
+	 many by a helmfile.yaml, such as one written by export.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk import called")
+			if importFrom != "" {
+				importReleases(helmfileReleases(importFrom))
+			} else {
+				importReleases([]*release.Release{flagRelease()})
+			}
+		},
+	}
+	importChart     string
+	importValues    []string
+	importName      string
+	importNamespace string
+	importFrom      string
+	importReplace   bool
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importChart, "chart", "",
+		"Chart directory or .tgz of the Release to import")
+	importCmd.Flags().StringArrayVar(&importValues, "values", nil,
+		"Values file of the Release to import. Can be repeated, with later files"+
+			" taking precedence")
+	importCmd.Flags().StringVar(&importName, "name", "",
+		"Name of the Release to import")
+	importCmd.Flags().StringVar(&importNamespace, "namespace", "default",
+		"Namespace of the Release to import")
+	importCmd.Flags().StringVar(&importFrom, "from", "",
+		"helmfile.yaml listing the Releases to import, in order, with their"+
+			" name, namespace, chart and values, instead of the flags above")
+	importCmd.Flags().BoolVar(&importReplace, "replace", false,
+		"Replace Releases of the same name already in File, rather than failing")
+	rootCmd.AddCommand(importCmd)
+}
+
+//flagRelease returns the Release given by the --chart, --values, --name and
+//--namespace flags
+func flagRelease() *release.Release {
+	if importChart == "" || importName == "" {
+		panic("--chart and --name are required, unless --from is set")
+	}
+	return buildRelease(importName, importNamespace, importChart, importValues)
+}
+
+//helmfileReleases returns the Releases listed in the helmfile.yaml
+func helmfileReleases(file string) (releases []*release.Release) {
+	helmfile, err := utils.ReadHelmfile(file)
+	utils.PanicCheck(err)
+	for _, rel := range helmfile.Releases {
+		namespace := rel.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		releases = append(releases, buildRelease(rel.Name, namespace,
+			rel.Chart, rel.Values))
+	}
+	return
+}
+
+//buildRelease returns a Release of the chart, with the merged values files
+func buildRelease(name, namespace, chartPath string,
+	valuesFiles []string) *release.Release {
+	chart, err := chartutil.Load(chartPath)
+	utils.PanicCheck(err)
+	values := ""
+	if len(valuesFiles) > 0 {
+		values, err = utils.ReadValues(valuesFiles)
+		utils.PanicCheck(err)
+	}
+	log.Println("Release:", name, "built from chart",
+		chart.GetMetadata().GetName()+"-"+chart.GetMetadata().GetVersion())
+	return utils.NewRelease(name, namespace, chart, values)
+}
+
+//importReleases adds the Releases to the end of File, creating it if need be,
+//or replaces those of the same name with --replace
+func importReleases(imported []*release.Release) {
+	location := archiveFilename()
+	releases, metadata, found := readReleases(location)
+	if !found {
+		log.Println("Creating archive:", location)
+	}
+	for _, release := range imported {
+		i := utils.ReleaseIndex(release.GetName(), releases)
+		switch {
+		case i < 0:
+			releases = append(releases, release)
+		case importReplace:
+			releases[i] = release
+		default:
+			panic("Release: " + release.GetName() + " is already in File, use" +
+				" --replace to replace it")
+		}
+	}
+	writeReleases(location, releases, metadata)
+	log.Println("Imported", len(imported), "Helm Releases to", location)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestImportReleases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-bulk-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file = filepath.Join(dir, "helm-releases.tar.gz")
+	defer func() { file = "" }()
+	importReleases([]*release.Release{
+		utils.NewRelease("app", "web", nil, "a: 1\n"),
+		utils.NewRelease("db", "data", nil, ""),
+	})
+	importReplace = true
+	defer func() { importReplace = false }()
+	importReleases([]*release.Release{utils.NewRelease("app", "web", nil,
+		"a: 2\n")})
+	releases, _, found := readReleases(file)
+	if !found || len(releases) != 2 || releases[0].GetName() != "app" ||
+		releases[0].GetConfig().GetRaw() != "a: 2\n" {
+		t.Errorf("Imported Releases were incorrect, got: %v.", releases)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	lintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check Releases in File for APIs removed from a Kubernetes version",
+		Long: `This command will scan the manifests and hooks of each Release in
+	 File for apiVersions that are deprecated or removed in the given
+	 Kubernetes version, exiting non-zero if any are removed.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk lint called")
+			if !lintReleases(Releases(), lintKubeVersion) {
+				os.Exit(1)
+			}
+		},
+	}
+	lintKubeVersion string
+	loadLintVersion string
+)
+
+func init() {
+	lintCmd.Flags().StringVar(&lintKubeVersion, "kube-version", "",
+		"Kubernetes version to check against, e.g. 1.22")
+	lintCmd.Flags().BoolVar(&latest, "latest", false,
+		"Lint the newest archive written with 'save --timestamp'")
+	rootCmd.AddCommand(lintCmd)
+	loadCmd.Flags().StringVar(&loadLintVersion, "lint-kube-version", "",
+		"Before loading, check Releases for APIs removed from this Kubernetes"+
+			" version, e.g. 1.22, and refuse to load if any are")
+}
+
+//lintReleases logs a report of the resources in the Releases using apiVersions
+//deprecated or removed in the Kubernetes version, returning false if any are
+//removed
+func lintReleases(releases []*release.Release, kubeVersion string) bool {
+	if kubeVersion == "" {
+		panic("--kube-version is required")
+	}
+	var findings []utils.LintFinding
+	removed := 0
+	for _, release := range releases {
+		releaseFindings, err := utils.LintRelease(release, kubeVersion)
+		utils.PanicCheck(err)
+		for _, finding := range releaseFindings {
+			if finding.Removed {
+				removed++
+			}
+		}
+		findings = append(findings, releaseFindings...)
+	}
+	logLintReport(findings, kubeVersion)
+	log.Println(len(findings), "deprecated or removed APIs found in",
+		len(releases), "Releases,", removed, "removed in Kubernetes",
+		kubeVersion)
+	return removed == 0
+}
+
+//logLintReport logs a table of the lint findings
+func logLintReport(findings []utils.LintFinding, kubeVersion string) {
+	if len(findings) == 0 {
+		return
+	}
+	var buffer bytes.Buffer
+	addHeaderToBuffer("Lint report for Kubernetes "+kubeVersion+":", &buffer)
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "    RELEASE\tRESOURCE\tSTATUS\tREPLACEMENT")
+	for _, finding := range findings {
+		fmt.Fprintf(w, "    %s\t%s\t%s\t%s\n", finding.Release,
+			finding.Resource, finding.Status(), finding.Deprecation.Replacement)
+	}
+	w.Flush()
+	log.Println(buffer.String())
+}
@@ -16,12 +16,11 @@ package cmd
 
 import (
 	"bytes"
-	"io/ioutil"
+	"errors"
 	"log"
 	"os"
-	"strings"
+	"time"
 
-	"github.com/mholt/archiver"
 	"github.com/ovotech/helm-bulk/utils"
 	"github.com/spf13/cobra"
 	"k8s.io/helm/pkg/helm"
@@ -37,45 +36,62 @@ var (
 	 and 'Helm install' those Releases with the same Chart and Values.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			log.Println("helm-bulk load called")
-			client := utils.Client(tlsKey, tlsCert, caCert, tlsServerName, disableTLS)
+			client := newClient()
+			releasePrefs = utils.ReleasePrefs(orderPrefConfigDir)
+			var err error
+			statusPolicy, err = utils.NewStatusPolicy(
+				utils.StatusPolicyPrefs(orderPrefConfigDir))
+			utils.PanicCheck(err)
+			checkHookMode(hooks)
+			if !utils.ValidIdentity(releaseIdentity) {
+				panic("Unknown release identity '" + releaseIdentity +
+					"', must be " + utils.IdentityName + " or " +
+					utils.IdentityNamespaceName)
+			}
+			if transaction && delete {
+				panic("--transaction can't be combined with --delete, as purged" +
+					" Releases can't be restored")
+			}
+			if transaction && resume {
+				panic("--transaction can't be combined with --resume, as the" +
+					" previous load's pre-load revisions aren't known")
+			}
 			if dryRun {
 				log.Println("*** operating in dry-run mode ***")
 			}
-			loadedReleases := Releases()
+			store, name := sourceArchive()
+			loadedReleases, metadata := storedReleases(store, name)
+			checkVersions(metadata, client)
+			checkCluster(metadata, newCluster())
 			if len(loadedReleases) > 0 {
 				logReleases(loadedReleases, "Helm Releases present in File:")
 			} else {
 				panic("No Helm Releases found, they're essential for the Load cmd")
 			}
-			var updateReleases []*release.Release
-			if upgrade || delete {
-				_, updateReleases = splitReleases(loadedReleases, client)
-				if upgrade {
-					logReleases(updateReleases, "Existing Helm Releases to update:")
-				} else {
-					logReleases(updateReleases,
-						"Existing Helm Releases to purge (prior to reinstall):")
-					purge(updateReleases, client)
-				}
-			}
-			//split Releases a 2nd time, updateReleases may be different now if a delete
-			//has just happened.
-			installReleases, updateReleases := splitReleases(loadedReleases, client)
-			if len(installReleases) > 0 {
-				logReleases(installReleases, "Helm Releases to install:")
-			} else if !delete && !upgrade {
-				log.Println("No Releases found to install, maybe they already exist" +
-					" in the Cluster?")
-				os.Exit(0)
-			} else {
-				log.Println("No Releases found to delete or upgrade")
-			}
+			substituteCharts(loadedReleases)
+			checkBeforeLoad(loadedReleases, client)
+			openJournal(store, name)
+			installReleases, updateReleases := plan(loadedReleases, client)
 			load(installReleases, updateReleases, client)
 		},
 	}
-	dryRun  bool
-	upgrade bool
-	delete  bool
+	dryRun          bool
+	upgrade         bool
+	delete          bool
+	wait            bool
+	timeout         time.Duration
+	hooks           string
+	force           bool
+	reuseValues     bool
+	resetValues     bool
+	recreatePods    bool
+	atomic          bool
+	transaction     bool
+	yes             bool
+	resume          bool
+	releaseIdentity string
+	statusPolicy    utils.StatusPolicy
+	releasePrefs    map[string]utils.ReleasePref
 )
 
 func init() {
@@ -85,7 +101,42 @@ func init() {
 	loadCmd.Flags().BoolVarP(&upgrade, "upgrade", "u", false,
 		"Upgrade existing Releases")
 	loadCmd.Flags().BoolVarP(&delete, "delete", "d", false,
-		"Delete existing Releases")
+		"Purge existing Releases before reinstalling them, after saving them to a"+
+			" safety archive. Prompts for confirmation unless --yes is set")
+	loadCmd.Flags().BoolVar(&wait, "wait", false,
+		"Wait until each Release's resources are ready before loading the next;"+
+			" Releases after one that doesn't become ready are skipped")
+	loadCmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute,
+		"Time to wait for each Tiller operation, including --wait")
+	loadCmd.Flags().StringVar(&hooks, "hooks", utils.HooksNone,
+		"Which Release hooks to run: all, none, pre-only or post-only")
+	loadCmd.Flags().BoolVar(&force, "force", true,
+		"Force resource updates through delete/recreate on upgrade")
+	loadCmd.Flags().BoolVar(&reuseValues, "reuse-values", true,
+		"Merge the saved values into the existing Release's values on upgrade")
+	loadCmd.Flags().BoolVar(&resetValues, "reset-values", false,
+		"Reset the existing Release's values to the chart's defaults before"+
+			" applying the saved values on upgrade, overriding --reuse-values")
+	loadCmd.Flags().BoolVar(&recreatePods, "recreate-pods", false,
+		"Restart the Pods of upgraded Releases")
+	loadCmd.Flags().BoolVar(&atomic, "atomic", false,
+		"Roll back a failed upgrade to the previous revision, and purge a failed"+
+			" install. Implies --wait")
+	loadCmd.Flags().BoolVar(&transaction, "transaction", false,
+		"If any Release fails to load, roll back upgraded Releases and purge"+
+			" installed ones, in reverse order. Can't be combined with --delete")
+	loadCmd.Flags().BoolVarP(&yes, "yes", "y", false,
+		"Don't prompt for confirmation before purging Releases with --delete")
+	loadCmd.Flags().BoolVar(&resume, "resume", false,
+		"Resume an interrupted load, skipping Releases that the journal shows"+
+			" were already purged or loaded. Can't be combined with --transaction")
+	loadCmd.Flags().StringVar(&releaseIdentity, "release-identity",
+		utils.IdentityNamespaceName, "How saved Releases are matched with"+
+			" installed ones: by namespace-name, where a same-named Release in"+
+			" another namespace is reported as a conflict and skipped, or by name"+
+			" alone")
+	loadCmd.Flags().BoolVar(&latest, "latest", false,
+		"Load from the newest archive written with 'save --timestamp'")
 	rootCmd.AddCommand(loadCmd)
 }
 
@@ -119,103 +170,266 @@ func logReleases(releases []*release.Release, header string) {
 
 //Releases decodes the Release file and returns a slice of Releases
 func Releases() (releases []*release.Release) {
-	wd, err := os.Getwd()
-	utils.PanicCheck(err)
-	utils.PanicCheck(archiver.TarGz.Open(archiveFilename(), wd))
-	dat, err := ioutil.ReadFile(textFilename())
+	releases, _ = sourceReleases()
+	return
+}
+
+//sourceReleases decodes the Release file, returning its Releases and metadata
+func sourceReleases() (releases []*release.Release,
+	metadata *utils.ArchiveMetadata) {
+	return storedReleases(sourceArchive())
+}
+
+//storedReleases decodes the named archive in the Store, returning its Releases
+//and metadata
+func storedReleases(store utils.Store, name string) (
+	releases []*release.Release, metadata *utils.ArchiveMetadata) {
+	archive, err := store.Get(name)
 	utils.PanicCheck(err)
-	for _, splitString := range strings.Split(string(dat), ",") {
-		release, err := utils.DecodeRelease(splitString)
-		utils.PanicCheck(err)
-		releases = append(releases, release)
+	defer archive.Close()
+	return decodeArchive(archive)
+}
+
+//releaseSplit is the result of comparing Releases loaded from file with those
+//currently installed
+type releaseSplit struct {
+	//install holds loaded Releases that aren't installed
+	install []*release.Release
+	//upgrade holds loaded Releases that are installed
+	upgrade []*release.Release
+	//existing holds the installed versions of the Releases in upgrade
+	existing []*release.Release
+	//conflicts holds loaded Releases whose name is used by an installed Release
+	//in another namespace, which are neither installed nor upgraded
+	conflicts []*release.Release
+	//repair holds loaded Releases that are installed but in a status that the
+	//status policy says to upgrade, e.g. FAILED, regardless of --upgrade
+	repair []*release.Release
+	//skip holds loaded Releases that are installed but in a status that the
+	//status policy says to leave alone, e.g. PENDING_UPGRADE
+	skip []*release.Release
+}
+
+//plan works out which loaded Releases to install and which to upgrade, first
+//purging existing Releases if --delete is set
+func plan(loadedReleases []*release.Release,
+	client helm.Interface) (installReleases, updateReleases []*release.Release) {
+	if delete {
+		split := splitReleases(loadedReleases, client)
+		logReleases(split.existing,
+			"Existing Helm Releases to purge (prior to reinstall):")
+		purge(split.existing, client)
+	}
+	//split Releases (again, if a delete has just happened)
+	split := splitReleases(loadedReleases, client)
+	logReleases(split.conflicts, "Helm Releases skipped due to conflicts:")
+	logReleases(split.skip,
+		"Helm Releases skipped due to their installed status:")
+	updateReleases = split.repair
+	logReleases(split.repair,
+		"Existing Helm Releases to repair with an upgrade:")
+	if upgrade {
+		updateReleases = append(updateReleases, split.upgrade...)
+		logReleases(split.upgrade, "Existing Helm Releases to update:")
 	}
-	os.Remove(textFilename())
+	installReleases = split.install
+	logInstallPlan(installReleases, updateReleases)
 	return
 }
 
-//splitReleases obtains a slice of currently installed Releases, which it uses
-//along with the provided slice of Releases loaded from file, to compose and
-//return two slices; one for Releases to be installed, and another for Releases
-//to be upgraded
+//logInstallPlan logs the Releases to install, exiting if there's nothing to
+//load at all
+func logInstallPlan(installReleases, updateReleases []*release.Release) {
+	if len(installReleases) > 0 {
+		logReleases(installReleases, "Helm Releases to install:")
+	} else if !delete && !upgrade && len(updateReleases) == 0 {
+		log.Println("No Releases found to install, maybe they already exist" +
+			" in the Cluster?")
+		os.Exit(0)
+	} else if len(updateReleases) == 0 {
+		log.Println("No Releases found to delete or upgrade")
+	}
+}
+
+//splitReleases obtains a slice of currently installed Releases in any status,
+//which it compares with the provided slice of Releases loaded from file,
+//according to the --release-identity and status policy, to work out which are
+//to be installed and which already exist
 func splitReleases(loadedReleases []*release.Release,
-	client *helm.Client) (releases, existingReleases []*release.Release) {
-	var statusFilter = helm.ReleaseListStatuses([]release.Status_Code{
-		release.Status_DEPLOYED,
-	})
+	client helm.Interface) (split releaseSplit) {
+	var statusFilter = helm.ReleaseListStatuses(statusPolicy.Statuses())
 	releaseResp, err := client.ListReleases(statusFilter)
 	utils.PanicCheck(err)
-	for _, release := range releaseResp.GetReleases() {
-		existingReleases = append(existingReleases, release)
-	}
-	utils.PanicCheck(err)
+	existingReleases := utils.LatestRevisions(releaseResp.GetReleases())
 	for _, release := range loadedReleases {
-		if !utils.ContainsRelease(release, existingReleases) {
-			releases = append(releases, release)
+		if existing := utils.MatchRelease(release, existingReleases,
+			releaseIdentity); existing != nil {
+			warnNamespaceMismatch(release, existing)
+			split.addExisting(release, existing)
+		} else if conflict := utils.ConflictingRelease(release,
+			existingReleases); conflict != nil {
+			log.Println("Release:", release.GetName(), "saved in namespace",
+				release.GetNamespace(), "conflicts with the installed Release in"+
+					" namespace", conflict.GetNamespace())
+			split.conflicts = append(split.conflicts, release)
+		} else {
+			split.install = append(split.install, release)
 		}
 	}
 	return
 }
 
+//addExisting adds a loaded Release that's already installed to the split,
+//according to the status policy's action for the installed Release's status
+func (split *releaseSplit) addExisting(loaded, existing *release.Release) {
+	status := existing.GetInfo().GetStatus().GetCode()
+	switch statusPolicy.Action(existing) {
+	case utils.StatusActionExisting:
+		split.upgrade = append(split.upgrade, loaded)
+		split.existing = append(split.existing, existing)
+	case utils.StatusActionUpgrade:
+		log.Println("Release:", loaded.GetName(), "is", status.String()+
+			", it will be upgraded")
+		split.repair = append(split.repair, loaded)
+	case utils.StatusActionReinstall:
+		log.Println("Release:", loaded.GetName(), "is", status.String()+
+			", it will be reinstalled")
+		split.install = append(split.install, loaded)
+	default:
+		log.Println("WARNING: Release:", loaded.GetName(), "is", status.String()+
+			", it will be skipped")
+		split.skip = append(split.skip, loaded)
+	}
+}
+
+//warnNamespaceMismatch logs a warning if a loaded Release matched an installed
+//Release in a different namespace, as can happen with --release-identity name
+func warnNamespaceMismatch(loaded, existing *release.Release) {
+	if loaded.GetNamespace() != existing.GetNamespace() {
+		log.Println("WARNING: Release:", loaded.GetName(), "saved in namespace",
+			loaded.GetNamespace(), "matches the installed Release in namespace",
+			existing.GetNamespace())
+	}
+}
+
 //load iterates through first the Releases that need Installing, then those
-//that need Upgrading, invoking the func that actually runs through the loading
+//that need Upgrading, invoking the func that actually runs through the loading,
+//and finally logs a summary of the results
 func load(installReleases, updateReleases []*release.Release,
-	client *helm.Client) {
-	if !dryRun {
+	client helm.Interface) {
+	if dryRun {
+		logLoadPlan(installReleases, true)
+		logLoadPlan(updateReleases, false)
+	} else {
+		revisions := recordPreLoadRevisions(installReleases, updateReleases,
+			client)
+		var results []loadResult
 		for _, release := range installReleases {
-			loadRelease(release, true, client)
+			results = append(results, loadOrSkip(release, true, results, client))
 		}
 		for _, release := range updateReleases {
-			loadRelease(release, false, client)
+			results = append(results, loadOrSkip(release, false, results, client))
 		}
+		logSummary(results)
+		if transaction && firstFailure(results) != "" {
+			compensate(results, revisions, client)
+		}
+	}
+}
+
+//logLoadPlan logs the settings each of the provided Releases would be
+//installed or upgraded with
+func logLoadPlan(releases []*release.Release, install bool) {
+	for _, release := range releases {
+		releaseName := release.GetName()
+		log.Println("would", opString(install), "Release:", releaseName, "with",
+			releaseLoadOptions(releaseName).describe(install))
+	}
+}
+
+//loadOrSkip loads the provided Release, unless it was loaded by the load being
+//resumed, or a Release earlier in the order was waited on and failed to become
+//ready, in which case it's skipped as it may depend on that Release, or any
+//earlier Release failed in a transaction
+func loadOrSkip(release *release.Release, install bool, results []loadResult,
+	client helm.Interface) loadResult {
+	releaseName := release.GetName()
+	if op := loadedPreviously(releaseName); op != "" {
+		log.Println("skipping Release:", releaseName, "as its", op,
+			"completed in the load being resumed")
+		return loadResult{release: releaseName, op: op, status: resumedStatus}
+	}
+	if reason := skipReason(results); reason != "" {
+		log.Println("skipping Release:", releaseName, "as", reason)
+		return loadResult{release: releaseName, op: opString(install),
+			status: skippedStatus, err: errors.New(reason)}
 	}
+	return loadRelease(release, install, client)
+}
+
+//skipReason returns why the next Release shouldn't be loaded given the results
+//so far, or an empty string if it should
+func skipReason(results []loadResult) string {
+	if blocker := notReady(results); blocker != "" {
+		return blocker + " did not become ready"
+	}
+	if failed := firstFailure(results); transaction && failed != "" {
+		return "the transaction was aborted when " + failed + " failed"
+	}
+	return ""
 }
 
 //loadRelease attempts to Install or Upgrade (depending on whether the Release
-//has previously been installed or not) the provided Release.
-//If an error is encountered in doing so, it logs the failure and skips to the
-//next element in the slice
-func loadRelease(release *release.Release, install bool, client *helm.Client) {
+//has previously been installed or not) the provided Release, and returns the
+//result. If an error is encountered in doing so, it logs the failure, undoes
+//the load if --atomic is set, and skips to the next element in the slice
+func loadRelease(release *release.Release, install bool,
+	client helm.Interface) (result loadResult) {
 	releaseName := release.GetName()
 	log.Println("loading Release:", releaseName)
-	var statusString string
-	var err error
-	if install {
-		options := installOptions(release)
-		var resp *rls.InstallReleaseResponse
-		resp, err = client.InstallReleaseFromChart(release.Chart,
-			release.GetNamespace(), options...)
-		statusString = resp.GetRelease().GetInfo().GetStatus().GetCode().String()
-	} else {
-		updateOptions := updateOptions(release)
-		var resp *rls.UpdateReleaseResponse
-		resp, err = client.UpdateReleaseFromChart(releaseName, release.Chart,
-			updateOptions...)
-		statusString = resp.GetRelease().GetInfo().GetStatus().GetCode().String()
-	}
-	if err != nil {
-		logReleaseFail(releaseName, err)
+	opts := releaseLoadOptions(releaseName)
+	result = loadResult{release: releaseName, op: opString(install),
+		waited: opts.wait}
+	revision := preLoadRevision(releaseName, install, client)
+	start := time.Now()
+	result.status, result.err = sendRelease(release, install, opts, client)
+	result.duration = time.Since(start)
+	recordProgress(releaseName, result.op, result.err)
+	if result.err != nil {
+		logReleaseFail(releaseName, result.err)
+		if atomic {
+			result.rollback, result.undone = undoLoad(releaseName, install, revision,
+				opts, client)
+		}
 	} else {
-		logReleaseStatusCode(releaseName, statusString, install)
+		logReleaseStatusCode(releaseName, result.status, install)
 	}
+	return
 }
 
-//purge deletes the provided releases
-func purge(releasesToPurge []*release.Release, client *helm.Client) {
-	if !dryRun && len(releasesToPurge) > 0 {
-		var buffer bytes.Buffer
-		buffer.WriteString("About to purge existing releases:")
-		buffer.WriteString("\n\n")
-		addReleasesToBuffer(releasesToPurge, &buffer)
-		log.Println(buffer.String())
-		for _, release := range releasesToPurge {
-			releaseName := release.GetName()
-			log.Println("Purging Release:", releaseName)
-			resp, err := client.DeleteRelease(releaseName, deleteOptions()...)
-			utils.PanicCheck(err)
-			log.Println(releaseName, "helm delete response status:",
-				resp.GetRelease().GetInfo().GetStatus().GetCode().String())
-		}
+//sendRelease sends the install or upgrade request for the Release to Tiller,
+//returning the resulting status
+func sendRelease(release *release.Release, install bool, opts loadOptions,
+	client helm.Interface) (status string, err error) {
+	chart, missing := utils.ChartForHookMode(release.Chart, release.Hooks,
+		opts.hooks)
+	for _, path := range missing {
+		log.Println("WARNING: Release:", release.GetName(), "saved hook", path,
+			"isn't in the chart being loaded, so --hooks", opts.hooks,
+			"may run hooks it was meant to skip")
+	}
+	if install {
+		var resp *rls.InstallReleaseResponse
+		resp, err = client.InstallReleaseFromChart(chart,
+			release.GetNamespace(), installOptions(release, opts)...)
+		status = resp.GetRelease().GetInfo().GetStatus().GetCode().String()
+	} else {
+		var resp *rls.UpdateReleaseResponse
+		resp, err = client.UpdateReleaseFromChart(release.GetName(), chart,
+			updateOptions(release, opts)...)
+		status = resp.GetRelease().GetInfo().GetStatus().GetCode().String()
 	}
+	return
 }
 
 //logReleaseFail logs the string and error, with some added formatting
@@ -227,13 +441,16 @@ func logReleaseFail(releaseName string, err error) {
 //logReleaseStatusCode logs the strings with some added formatting, including
 //what install/upgrade op it relates to
 func logReleaseStatusCode(releaseName, statusString string, install bool) {
-	var opString string
+	log.Println(releaseName, "helm", opString(install), "response status:",
+		statusString)
+}
+
+//opString returns the name of the install/upgrade op
+func opString(install bool) string {
 	if install {
-		opString = "install"
-	} else {
-		opString = "upgrade"
+		return "install"
 	}
-	log.Println(releaseName, "helm", opString, "response status:", statusString)
+	return "upgrade"
 }
 
 //deleteOptions creates and returns a slice of DeleteOptions
@@ -249,27 +466,31 @@ func deleteOptions() (deleteOptions []helm.DeleteOption) {
 
 //updateOptions creates and returns a slice of UpdateOptions, of which the
 //ValueOverrides are obtained from the provided Release
-func updateOptions(release *release.Release) (updateOptions []helm.UpdateOption) {
-	disableHooks := true
-	reuseValues := true
-	forceUpgrade := true
+func updateOptions(release *release.Release,
+	opts loadOptions) (updateOptions []helm.UpdateOption) {
+	disableHooks := opts.hooks == utils.HooksNone
 	updateDryRun := false
 	cv := release.GetConfig()
 	overrides := []byte(cv.Raw)
 	updateOptions = []helm.UpdateOption{
 		helm.UpdateValueOverrides(overrides),
 		helm.UpgradeDryRun(updateDryRun),
-		helm.ReuseValues(reuseValues),
-		helm.UpgradeForce(forceUpgrade),
+		helm.ReuseValues(opts.reuseValues),
+		helm.ResetValues(opts.resetValues),
+		helm.UpgradeRecreate(opts.recreatePods),
+		helm.UpgradeForce(opts.force),
 		helm.UpgradeDisableHooks(disableHooks),
+		helm.UpgradeWait(opts.wait),
+		helm.UpgradeTimeout(int64(opts.timeout.Seconds())),
 	}
 	return
 }
 
 //installOptions creates and returns a slice of InstallOptions, of which some
 //fields are grabbed from the provided release
-func installOptions(release *release.Release) (installOptions []helm.InstallOption) {
-	disableHooks := true
+func installOptions(release *release.Release,
+	opts loadOptions) (installOptions []helm.InstallOption) {
+	disableHooks := opts.hooks == utils.HooksNone
 	reuseName := true
 	installDryRun := false
 	releaseName := release.GetName()
@@ -281,6 +502,8 @@ func installOptions(release *release.Release) (installOptions []helm.InstallOpti
 		helm.ReleaseName(releaseName),
 		helm.InstallReuseName(reuseName),
 		helm.InstallDisableHooks(disableHooks),
+		helm.InstallWait(opts.wait),
+		helm.InstallTimeout(int64(opts.timeout.Seconds())),
 	}
 	return
 }
@@ -0,0 +1,89 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ovotech/helm-bulk/utils"
+)
+
+//loadOptions holds the settings used to load a single Release, from the load
+//flags and any per-Release preferences in the config
+type loadOptions struct {
+	wait         bool
+	timeout      time.Duration
+	hooks        string
+	force        bool
+	reuseValues  bool
+	resetValues  bool
+	recreatePods bool
+}
+
+//releaseLoadOptions returns the loadOptions for the named Release, with any
+//per-Release preferences overriding the load flags
+func releaseLoadOptions(releaseName string) (opts loadOptions) {
+	opts = loadOptions{wait: wait, timeout: timeout, hooks: hooks,
+		force: force, reuseValues: reuseValues, resetValues: resetValues,
+		recreatePods: recreatePods}
+	pref := releasePrefs[releaseName]
+	if pref.Timeout > 0 {
+		opts.timeout = pref.Timeout
+	}
+	if pref.Hooks != "" {
+		opts.hooks = pref.Hooks
+	}
+	overrideBool(&opts.wait, pref.Wait)
+	overrideBool(&opts.force, pref.Force)
+	overrideBool(&opts.reuseValues, pref.ReuseValues)
+	overrideBool(&opts.resetValues, pref.ResetValues)
+	overrideBool(&opts.recreatePods, pref.RecreatePods)
+	if opts.resetValues {
+		opts.reuseValues = false
+	}
+	opts.wait = opts.wait || atomic
+	checkHookMode(opts.hooks)
+	return
+}
+
+//overrideBool sets target to the preference, if one is defined
+func overrideBool(target *bool, pref *bool) {
+	if pref != nil {
+		*target = *pref
+	}
+}
+
+//checkHookMode panics if the provided hook mode isn't a known one
+func checkHookMode(mode string) {
+	if !utils.ValidHookMode(mode) {
+		panic("Unknown hooks mode '" + mode + "', must be one of " +
+			utils.HooksAll + ", " + utils.HooksNone + ", " + utils.HooksPreOnly +
+			" or " + utils.HooksPostOnly)
+	}
+}
+
+//describe returns a description of the settings used for the install or
+//upgrade op, as logged in dry-run mode
+func (opts loadOptions) describe(install bool) string {
+	common := fmt.Sprintf("wait=%t, timeout=%s, hooks=%s", opts.wait,
+		opts.timeout, opts.hooks)
+	if install {
+		return common
+	}
+	return fmt.Sprintf("%s, force=%t, reuse-values=%t, reset-values=%t,"+
+		" recreate-pods=%t", common, opts.force, opts.reuseValues,
+		opts.resetValues, opts.recreatePods)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ovotech/helm-bulk/utils"
+)
+
+func TestReleaseLoadOptions(t *testing.T) {
+	noWait, noForce, reset := false, false, true
+	wait, timeout = true, 5*time.Minute
+	releasePrefs = map[string]utils.ReleasePref{
+		"slow":   {Timeout: 20 * time.Minute, Hooks: "pre-only"},
+		"nowait": {Wait: &noWait},
+		"reset":  {ResetValues: &reset, Force: &noForce},
+	}
+	defer func() { wait, releasePrefs = false, nil }()
+	for releaseName, expected := range map[string]loadOptions{
+		"default": {wait: true, timeout: 5 * time.Minute, hooks: "none",
+			force: true, reuseValues: true},
+		"slow": {wait: true, timeout: 20 * time.Minute, hooks: "pre-only",
+			force: true, reuseValues: true},
+		"nowait": {wait: false, timeout: 5 * time.Minute, hooks: "none",
+			force: true, reuseValues: true},
+		"reset": {wait: true, timeout: 5 * time.Minute, hooks: "none",
+			resetValues: true},
+	} {
+		actual := releaseLoadOptions(releaseName)
+		if actual != expected {
+			t.Errorf("loadOptions for %s were incorrect, got: %+v, want: %+v.",
+				releaseName, actual, expected)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
@@ -31,3 +34,121 @@ func TestAddHeaderToBuffer(t *testing.T) {
 			actualString, expectedString)
 	}
 }
+
+func TestSkipReason(t *testing.T) {
+	results := []loadResult{{release: "app", err: errors.New("failed")}}
+	if actualString := skipReason(results); actualString != "" {
+		t.Errorf("Release was skipped outside a transaction, got: %s",
+			actualString)
+	}
+	transaction = true
+	defer func() { transaction = false }()
+	actualString := skipReason(results)
+	expectedString := "the transaction was aborted when app failed"
+	if actualString != expectedString {
+		t.Errorf("Skip reason was incorrect, got: %s, want: %s.",
+			actualString, expectedString)
+	}
+}
+
+//installedRelease returns a mock installed Release with the provided status
+func installedRelease(name, namespace string,
+	status release.Status_Code) *release.Release {
+	return helm.ReleaseMock(&helm.MockReleaseOptions{Name: name,
+		Namespace: namespace, Version: 1, StatusCode: status})
+}
+
+//releaseNames returns the names of the Releases, in order
+func releaseNames(releases []*release.Release) (names []string) {
+	for _, release := range releases {
+		names = append(names, release.GetName())
+	}
+	return
+}
+
+func equalNames(actual []*release.Release, expected ...string) bool {
+	names := releaseNames(actual)
+	if len(names) != len(expected) {
+		return false
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitClient() *helm.FakeClient {
+	return &helm.FakeClient{Rels: []*release.Release{
+		installedRelease("web", "web", release.Status_DEPLOYED),
+		installedRelease("api", "other", release.Status_DEPLOYED),
+		installedRelease("broken", "broken", release.Status_FAILED),
+		installedRelease("unsaved", "unsaved", release.Status_DEPLOYED),
+	}}
+}
+
+func splitLoaded() []*release.Release {
+	return []*release.Release{
+		{Name: "web", Namespace: "web"},
+		{Name: "api", Namespace: "api"},
+		{Name: "broken", Namespace: "broken"},
+		{Name: "new", Namespace: "new"},
+	}
+}
+
+func TestSplitReleases(t *testing.T) {
+	defer func(identity string) {
+		releaseIdentity, statusPolicy = identity, nil
+	}(releaseIdentity)
+	statusPolicy = utils.DefaultStatusPolicy()
+	for _, c := range []struct {
+		identity  string
+		upgrade   []string
+		conflicts []string
+	}{
+		{utils.IdentityNamespaceName, []string{"web"}, []string{"api"}},
+		{utils.IdentityName, []string{"web", "api"}, nil},
+	} {
+		releaseIdentity = c.identity
+		split := splitReleases(splitLoaded(), splitClient())
+		if !equalNames(split.install, "new") ||
+			!equalNames(split.upgrade, c.upgrade...) ||
+			!equalNames(split.conflicts, c.conflicts...) ||
+			!equalNames(split.repair, "broken") {
+			t.Errorf("Incorrect split with identity %s, got: install %v, upgrade"+
+				" %v, conflicts %v, repair %v.", c.identity,
+				releaseNames(split.install), releaseNames(split.upgrade),
+				releaseNames(split.conflicts), releaseNames(split.repair))
+		}
+		if !equalNames(split.existing, c.upgrade...) {
+			t.Errorf("Releases not in File would be purged with identity %s,"+
+				" got: %v.", c.identity, releaseNames(split.existing))
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	defer func(identity string) {
+		releaseIdentity, statusPolicy, upgrade = identity, nil, false
+	}(releaseIdentity)
+	statusPolicy = utils.DefaultStatusPolicy()
+	for _, c := range []struct {
+		identity string
+		upgrade  bool
+		update   []string
+	}{
+		{utils.IdentityNamespaceName, false, []string{"broken"}},
+		{utils.IdentityNamespaceName, true, []string{"broken", "web"}},
+		{utils.IdentityName, false, []string{"broken"}},
+		{utils.IdentityName, true, []string{"broken", "web", "api"}},
+	} {
+		releaseIdentity, upgrade = c.identity, c.upgrade
+		install, update := plan(splitLoaded(), splitClient())
+		if !equalNames(install, "new") || !equalNames(update, c.update...) {
+			t.Errorf("Incorrect plan with identity %s and upgrade %t, got:"+
+				" install %v, update %v.", c.identity, c.upgrade,
+				releaseNames(install), releaseNames(update))
+		}
+	}
+}
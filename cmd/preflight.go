@@ -0,0 +1,317 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	preflightCmd = &cobra.Command{
+		Use:   "preflight",
+		Short: "Check Releases in File can be loaded into the Cluster",
+		Long: `This command will check, without changing anything, that File
+	 verifies, that its charts are compatible with the Tiller version, that its
+	 Release names don't clash with installed Releases, and, via kubectl, that
+	 its namespaces exist and the APIs its manifests use are served.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk preflight called")
+			client := newClient()
+			releases, problems := verifiedReleases()
+			if !preflight(releases, problems, client, newCluster()) {
+				os.Exit(1)
+			}
+		},
+	}
+	runPreflight bool
+)
+
+//Preflight check statuses; any FAIL stops a load
+const (
+	checkPass = "PASS"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+)
+
+//checkResult is the outcome of a preflight check
+type checkResult struct {
+	check  string
+	status string
+	detail string
+}
+
+func init() {
+	preflightCmd.Flags().BoolVarP(&disableTLS, "disable-tls", "t", false, "")
+	preflightCmd.Flags().BoolVar(&latest, "latest", false,
+		"Check the newest archive written with 'save --timestamp'")
+	rootCmd.AddCommand(preflightCmd)
+	loadCmd.Flags().BoolVar(&runPreflight, "preflight", false,
+		"Run the preflight checks before loading, and refuse to load if any fail")
+}
+
+//checkBeforeLoad runs the checks requested by --lint-kube-version and
+//--preflight, panicking before anything's changed if any fail
+func checkBeforeLoad(releases []*release.Release, client helm.Interface) {
+	if loadLintVersion != "" && !lintReleases(releases, loadLintVersion) {
+		panic("Releases use APIs removed from Kubernetes " + loadLintVersion +
+			", see the lint report above")
+	}
+	if runPreflight && !preflight(releases, nil, client, newCluster()) {
+		panic("Preflight checks failed, see the report above")
+	}
+}
+
+//verifiedReleases decodes the Releases in File, returning a problem for each
+//that can't be decoded rather than panicking
+func verifiedReleases() (releases []*release.Release, problems []string) {
+	store, name := sourceArchive()
+	archive, err := store.Get(name)
+	utils.PanicCheck(err)
+	defer archive.Close()
+	dat, _, err := utils.ReadArchive(archive)
+	if err != nil {
+		return nil, []string{"archive can't be read: " + err.Error()}
+	}
+	return verifiedDecode(dat)
+}
+
+//verifiedDecode decodes the comma separated Releases, returning a problem for
+//each that can't be decoded, or if there are none
+func verifiedDecode(dat []byte) (releases []*release.Release,
+	problems []string) {
+	for i, splitString := range strings.Split(string(dat), ",") {
+		if splitString == "" {
+			continue
+		}
+		release, err := utils.DecodeRelease(splitString)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Release %d can't be"+
+				" decoded: %v", i+1, err))
+			continue
+		}
+		releases = append(releases, release)
+	}
+	if len(releases) == 0 && len(problems) == 0 {
+		problems = append(problems, "archive has no Releases")
+	}
+	return
+}
+
+//preflight runs the preflight checks against the Releases, logging a report,
+//and returns false if any fail
+func preflight(releases []*release.Release, archiveProblems []string,
+	client helm.Interface, cluster utils.Cluster) bool {
+	results := checkArchive(releases, archiveProblems)
+	results = append(results, checkTillerVersion(releases, client)...)
+	results = append(results, checkNameClashes(releases, client)...)
+	results = append(results, checkNamespaces(releases, cluster)...)
+	results = append(results, checkAPIs(releases, cluster)...)
+	logPreflightReport(results)
+	for _, result := range results {
+		if result.status == checkFail {
+			return false
+		}
+	}
+	return true
+}
+
+//checkArchive fails for each problem decoding the archive, or with a Release
+//in it
+func checkArchive(releases []*release.Release,
+	problems []string) (results []checkResult) {
+	problems = append(problems, utils.VerifyReleases(releases)...)
+	for _, problem := range problems {
+		results = append(results, checkResult{"archive", checkFail, problem})
+	}
+	return passIfEmpty(results, "archive", fmt.Sprintf("%d Releases verified",
+		len(releases)))
+}
+
+//checkTillerVersion fails for each chart whose tillerVersion constraint isn't
+//satisfied by the Tiller version
+func checkTillerVersion(releases []*release.Release,
+	client helm.Interface) (results []checkResult) {
+	resp, err := client.GetVersion()
+	if err != nil {
+		return []checkResult{{"tiller version", checkFail, err.Error()}}
+	}
+	tillerVersion := resp.GetVersion().GetSemVer()
+	for _, release := range releases {
+		for _, chart := range utils.IncompatibleCharts(release, tillerVersion) {
+			results = append(results, checkResult{"tiller version", checkFail,
+				"Release " + release.GetName() + " chart " + chart +
+					" isn't satisfied by Tiller " + tillerVersion})
+		}
+	}
+	return passIfEmpty(results, "tiller version", "Tiller "+tillerVersion)
+}
+
+//checkNameClashes fails for each Release whose name is used by an installed
+//Release in another namespace. Matching by name alone, it warns instead, as the
+//installed Release is upgraded in its own namespace.
+func checkNameClashes(releases []*release.Release,
+	client helm.Interface) (results []checkResult) {
+	statusFilter := helm.ReleaseListStatuses(
+		utils.DefaultStatusPolicy().Statuses())
+	resp, err := client.ListReleases(statusFilter)
+	if err != nil {
+		return []checkResult{{"name clashes", checkFail, err.Error()}}
+	}
+	installed := utils.LatestRevisions(resp.GetReleases())
+	for _, release := range releases {
+		conflict := utils.ConflictingRelease(release, installed)
+		if conflict == nil {
+			continue
+		}
+		problem := "Release " + release.GetName() + " in namespace " +
+			release.GetNamespace() + " is installed in namespace " +
+			conflict.GetNamespace()
+		if releaseIdentity == utils.IdentityNamespaceName {
+			results = append(results, checkResult{"name clashes", checkFail,
+				problem})
+		} else {
+			results = append(results, checkResult{"name clashes", checkWarn,
+				problem + ", and will be upgraded there"})
+		}
+	}
+	return passIfEmpty(results, "name clashes", "none")
+}
+
+//checkNamespaces warns for each namespace that doesn't exist, which Tiller
+//creates on install
+func checkNamespaces(releases []*release.Release,
+	cluster utils.Cluster) (results []checkResult) {
+	namespaces, err := cluster.Namespaces()
+	if err != nil {
+		return []checkResult{{"namespaces", checkWarn, "skipped: " + err.Error()}}
+	}
+	missing := map[string]bool{}
+	for _, release := range releases {
+		namespace := release.GetNamespace()
+		if !namespaces[namespace] && !missing[namespace] {
+			missing[namespace] = true
+			results = append(results, checkResult{"namespaces", checkWarn,
+				"namespace " + namespace + " doesn't exist, Tiller will create it"})
+		}
+	}
+	return passIfEmpty(results, "namespaces", "all exist")
+}
+
+//checkAPIs fails for each resource whose apiVersion and kind isn't served by
+//the Cluster, nor defined by a CustomResourceDefinition in the Releases. If the
+//Cluster can't be queried, it warns and skips the remaining resources.
+func checkAPIs(releases []*release.Release,
+	cluster utils.Cluster) (results []checkResult) {
+	resources, owners, err := undefinedResources(releases)
+	if err != nil {
+		return []checkResult{{"apis", checkFail, err.Error()}}
+	}
+	served := map[string]map[string]bool{}
+	for _, resource := range resources {
+		if _, ok := served[resource.APIVersion]; !ok {
+			if served[resource.APIVersion], err = cluster.Kinds(
+				resource.APIVersion); err != nil {
+				results = append(results, checkResult{"apis", checkWarn,
+					"skipped: " + err.Error()})
+				break
+			}
+		}
+		if !served[resource.APIVersion][resource.Kind] {
+			key := apiKey(resource)
+			results = append(results, checkResult{"apis", checkFail, key +
+				" used by Release " + owners[key] + " isn't served by the Cluster"})
+		}
+	}
+	return passIfEmpty(results, "apis", "all served")
+}
+
+//undefinedResources returns a resource of each apiVersion and kind in the
+//Releases that isn't defined by a CustomResourceDefinition among them, along
+//with the first Release using each
+func undefinedResources(releases []*release.Release) (undefined []utils.Resource,
+	owners map[string]string, err error) {
+	resources, owners, err := releaseResources(releases)
+	if err != nil {
+		return nil, nil, err
+	}
+	defined, err := utils.DefinedKinds(resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, resource := range resources {
+		if !defined[apiKey(resource)] {
+			defined[apiKey(resource)] = true
+			undefined = append(undefined, resource)
+		}
+	}
+	return undefined, owners, nil
+}
+
+//releaseResources returns the resources in all the Releases, along with the
+//first Release using each apiVersion and kind
+func releaseResources(releases []*release.Release) (all []utils.Resource,
+	owners map[string]string, err error) {
+	owners = map[string]string{}
+	for _, release := range releases {
+		resources, err := utils.ReleaseResources(release)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, resource := range resources {
+			if _, ok := owners[apiKey(resource)]; !ok {
+				owners[apiKey(resource)] = release.GetName()
+			}
+		}
+		all = append(all, resources...)
+	}
+	return all, owners, nil
+}
+
+//apiKey identifies a resource's apiVersion and kind, e.g. "apps/v1 Deployment"
+func apiKey(resource utils.Resource) string {
+	return resource.APIVersion + " " + resource.Kind
+}
+
+//passIfEmpty returns the results, or a single passing result if there are none
+func passIfEmpty(results []checkResult, check, detail string) []checkResult {
+	if len(results) == 0 {
+		return []checkResult{{check, checkPass, detail}}
+	}
+	return results
+}
+
+//logPreflightReport logs a table of the preflight check results
+func logPreflightReport(results []checkResult) {
+	var buffer bytes.Buffer
+	addHeaderToBuffer("Preflight report:", &buffer)
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "    CHECK\tSTATUS\tDETAIL")
+	for _, result := range results {
+		fmt.Fprintf(w, "    %s\t%s\t%s\n", result.check, result.status,
+			result.detail)
+	}
+	w.Flush()
+	log.Println(buffer.String())
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//fakeCluster is a Cluster with one namespace, serving only apps/v1 Deployments
+type fakeCluster struct{}
+
+func (fakeCluster) Namespaces() (map[string]bool, error) {
+	return map[string]bool{"web": true}, nil
+}
+
+func (fakeCluster) Kinds(apiVersion string) (map[string]bool, error) {
+	return map[string]bool{"Deployment": apiVersion == "apps/v1"}, nil
+}
+
+func (fakeCluster) Context() (utils.ClusterContext, error) {
+	return utils.ClusterContext{Name: "staging",
+		Server: "https://staging:6443"}, nil
+}
+
+func preflightRelease(namespace, apiVersion string) *release.Release {
+	return &release.Release{Name: "app", Namespace: namespace,
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "app"}},
+		Manifest: "apiVersion: " + apiVersion + "\nkind: Deployment\n" +
+			"metadata:\n  name: app\n"}
+}
+
+func TestPreflight(t *testing.T) {
+	client := &helm.FakeClient{}
+	releases := []*release.Release{preflightRelease("web", "apps/v1")}
+	if !preflight(releases, nil, client, fakeCluster{}) {
+		t.Error("Preflight failed for a loadable Release")
+	}
+	releases = []*release.Release{preflightRelease("web", "extensions/v1beta1")}
+	if preflight(releases, nil, client, fakeCluster{}) {
+		t.Error("Preflight passed for a Release using an API that isn't served")
+	}
+	client.Rels = []*release.Release{preflightRelease("staging", "apps/v1")}
+	releases = []*release.Release{preflightRelease("web", "apps/v1")}
+	if preflight(releases, nil, client, fakeCluster{}) {
+		t.Error("Preflight passed for a Release installed in another namespace")
+	}
+}
+
+func TestCheckNameClashes(t *testing.T) {
+	defer func(identity string) { releaseIdentity = identity }(releaseIdentity)
+	client := &helm.FakeClient{
+		Rels: []*release.Release{preflightRelease("staging", "apps/v1")}}
+	releases := []*release.Release{preflightRelease("web", "apps/v1")}
+	for identity, status := range map[string]string{
+		utils.IdentityNamespaceName: checkFail,
+		utils.IdentityName:          checkWarn,
+	} {
+		releaseIdentity = identity
+		results := checkNameClashes(releases, client)
+		if len(results) != 1 || results[0].status != status {
+			t.Errorf("Name clash with --release-identity %s reported %v, want %s",
+				identity, results, status)
+		}
+	}
+}
+
+//brokenCluster is a fakeCluster that can't be asked about batch/v1
+type brokenCluster struct {
+	fakeCluster
+}
+
+func (brokenCluster) Kinds(apiVersion string) (map[string]bool, error) {
+	if apiVersion == "batch/v1" {
+		return nil, errors.New("kubectl failed")
+	}
+	return fakeCluster{}.Kinds(apiVersion)
+}
+
+func TestCheckAPIsKeepsFailures(t *testing.T) {
+	releases := []*release.Release{preflightRelease("web", "extensions/v1beta1"),
+		preflightRelease("web", "batch/v1")}
+	releases[1].Name = "job"
+	results := checkAPIs(releases, brokenCluster{})
+	if len(results) != 2 || results[0].status != checkFail ||
+		results[1].status != checkWarn {
+		t.Errorf("API check after kubectl failed reported %v, want a FAIL and"+
+			" a WARN", results)
+	}
+}
+
+func TestVerifiedDecode(t *testing.T) {
+	for _, dat := range []string{"", ","} {
+		releases, problems := verifiedDecode([]byte(dat))
+		if len(releases) != 0 || len(problems) != 1 ||
+			problems[0] != "archive has no Releases" {
+			t.Errorf("Decoding %q returned %v, %v, want no Releases problem",
+				dat, releases, problems)
+		}
+	}
+	releases, problems := verifiedDecode([]byte("AA=="))
+	if len(releases) != 0 || len(problems) != 1 {
+		t.Errorf("Decoding a truncated Release returned %v, %v, want a problem",
+			releases, problems)
+	}
+}
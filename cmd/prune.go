@@ -0,0 +1,72 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old timestamped archives according to a retention policy",
+		Long: `This command will list the archives written by 'save --timestamp'
+	 alongside the archive location, and delete those that aren't retained by the
+	 grandfather-father-son policy given by the --keep-* flags.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk prune called")
+			if dryRun {
+				log.Println("*** operating in dry-run mode ***")
+			}
+			prune()
+		},
+	}
+	retentionPolicy utils.RetentionPolicy
+)
+
+func init() {
+	pruneCmd.Flags().IntVar(&retentionPolicy.KeepLast, "keep-last", 0,
+		"Number of most recent archives to keep")
+	pruneCmd.Flags().IntVar(&retentionPolicy.KeepDaily, "keep-daily", 0,
+		"Number of days for which to keep the newest archive")
+	pruneCmd.Flags().IntVar(&retentionPolicy.KeepWeekly, "keep-weekly", 0,
+		"Number of weeks for which to keep the newest archive")
+	pruneCmd.Flags().BoolVarP(&dryRun, "dry-run", "r", false,
+		"Log the archives that would be deleted without deleting them")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+//prune deletes the timestamped archives that the retention policy doesn't keep
+func prune() {
+	if retentionPolicy == (utils.RetentionPolicy{}) {
+		panic("At least one of --keep-last, --keep-daily or --keep-weekly must" +
+			" be set, refusing to delete every archive")
+	}
+	store, name := archiveStore()
+	archives, err := utils.TimestampedArchives(store, name)
+	utils.PanicCheck(err)
+	remove := retentionPolicy.Prune(archives)
+	log.Println("Found", len(archives), "timestamped archives,", len(remove),
+		"to delete")
+	for _, archive := range remove {
+		log.Println("Deleting archive:", archive.Name)
+		if !dryRun {
+			utils.PanicCheck(store.Delete(archive.Name))
+		}
+	}
+}
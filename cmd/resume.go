@@ -0,0 +1,119 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+	"strings"
+
+	"github.com/ovotech/helm-bulk/utils"
+)
+
+//resumedStatus is the status of a Release that was loaded by the load being
+//resumed
+const resumedStatus = "COMPLETED PREVIOUSLY"
+
+var (
+	journalPath string
+	journal     *utils.Journal
+	completed   map[string]map[string]bool
+)
+
+func init() {
+	loadCmd.Flags().StringVar(&journalPath, "journal", "",
+		"Path of the journal recording load progress, for --resume. Defaults to"+
+			" next to a local archive; streamed and remote archives aren't"+
+			" journaled unless set")
+}
+
+//journalFilename returns the path of the load journal, which is --journal if
+//set, otherwise next to the archive when it's a local file. Streamed and remote
+//archives aren't journaled unless --journal is set.
+func journalFilename(store utils.Store, name string) string {
+	if journalPath != "" {
+		return journalPath
+	}
+	if path, ok := utils.LocalPath(store, name); ok {
+		return strings.TrimSuffix(path, ".tar.gz") + ".journal"
+	}
+	return ""
+}
+
+//openJournal reads the journal of the previous load when resuming, then opens
+//the journal of the named archive to record this load's progress. If it can't
+//be opened, e.g. in a read-only container, the load goes ahead without one.
+func openJournal(store utils.Store, name string) {
+	if dryRun {
+		return
+	}
+	filename := journalFilename(store, name)
+	if filename == "" {
+		if resume {
+			panic("--resume needs --journal to find the journal of a streamed" +
+				" or remote archive")
+		}
+		log.Println("Not journaling load progress, as the archive is streamed" +
+			" or remote; set --journal to journal it")
+		return
+	}
+	if resume {
+		readJournal(filename)
+	}
+	var err error
+	if journal, err = utils.OpenJournal(filename, resume); err != nil {
+		log.Println("WARNING: not journaling load progress, unable to open"+
+			" journal:", err.Error())
+	}
+}
+
+//readJournal reads the journal of the load being resumed
+func readJournal(filename string) {
+	entries, err := utils.ReadJournal(filename)
+	utils.PanicCheck(err)
+	completed = utils.CompletedActions(entries)
+	log.Println("Resuming load from", filename, "-", len(completed),
+		"Releases were processed previously")
+}
+
+//recordProgress records the outcome of an action on a Release in the journal
+func recordProgress(releaseName, action string, err error) {
+	if journal == nil {
+		return
+	}
+	outcome := utils.OutcomeOK
+	if err != nil {
+		outcome = utils.OutcomeFailed
+	}
+	if jerr := journal.Record(releaseName, action, outcome); jerr != nil {
+		log.Println("unable to write to load journal:", jerr.Error())
+	}
+}
+
+//loadedPreviously returns the op ("install" or "upgrade") that completed for
+//the Release in the load being resumed, or an empty string if neither did
+func loadedPreviously(releaseName string) string {
+	for _, op := range []string{opString(true), opString(false)} {
+		if completed[releaseName][op] {
+			return op
+		}
+	}
+	return ""
+}
+
+//processedPreviously returns whether any action completed for the Release in
+//the load being resumed, in which case it shouldn't be purged again
+func processedPreviously(releaseName string) bool {
+	return len(completed[releaseName]) > 0
+}
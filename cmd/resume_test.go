@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ovotech/helm-bulk/utils"
+)
+
+func TestJournalFilename(t *testing.T) {
+	for location, expected := range map[string]string{
+		"backups/helm-releases-20190101T000000Z.tar.gz": filepath.Join("backups",
+			"helm-releases-20190101T000000Z.journal"),
+		"s3://bucket/helm-releases.tar.gz": "",
+		"-":                                "",
+	} {
+		store, name, err := utils.NewStore(location)
+		utils.PanicCheck(err)
+		if actual := journalFilename(store, name); actual != expected {
+			t.Errorf("Journal filename for %s was incorrect, got: %s, want: %s.",
+				location, actual, expected)
+		}
+	}
+	journalPath = "/tmp/load.journal"
+	defer func() { journalPath = "" }()
+	store, name, _ := utils.NewStore("-")
+	if actual := journalFilename(store, name); actual != journalPath {
+		t.Errorf("--journal was ignored, got: %s", actual)
+	}
+}
+
+func TestOpenJournalWithoutWriteAccess(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-bulk-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "missing")
+	store, name, _ := utils.NewStore(filepath.Join(dir, "helm-releases.tar.gz"))
+	openJournal(store, name)
+	if journal != nil {
+		t.Error("Journal opened in a directory that doesn't exist")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Journal directory was created, got: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+)
+
+//revisionUnknown is the pre-load revision of a Release whose history couldn't
+//be obtained, so its load can't safely be undone
+const revisionUnknown int32 = -1
+
+//preLoadRevision returns the current revision of a Release that's about to be
+//loaded with --atomic, so a failed upgrade can be rolled back to it, and a failed
+//install only purged if it created a revision. It returns 0 if the Release
+//isn't installed, or --atomic isn't set.
+func preLoadRevision(releaseName string, install bool, client helm.Interface) int32 {
+	if !atomic {
+		return 0
+	}
+	revision, err := installedRevision(releaseName, client)
+	if err != nil {
+		log.Println("unable to get current revision of Release:", releaseName,
+			err.Error())
+		return revisionUnknown
+	}
+	return revision
+}
+
+//installedRevision returns the latest revision of the named Release, or 0 if
+//it has no history, i.e. it was never installed or has been purged
+func installedRevision(releaseName string,
+	client helm.Interface) (int32, error) {
+	resp, err := client.ReleaseHistory(releaseName, helm.WithMaxHistory(1))
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return 0, nil
+	}
+	if err != nil || len(resp.GetReleases()) == 0 {
+		return 0, err
+	}
+	return resp.GetReleases()[0].GetVersion(), nil
+}
+
+//currentRevision returns the latest revision of the named Release, which must
+//be installed
+func currentRevision(releaseName string, client helm.Interface) (int32, error) {
+	revision, err := installedRevision(releaseName, client)
+	if err == nil && revision == 0 {
+		err = fmt.Errorf("no history found for Release %s", releaseName)
+	}
+	return revision, err
+}
+
+//revisionCreated returns whether the named Release has a revision newer than
+//its pre-load revision, i.e. whether loading it changed it, or if not, why not
+func revisionCreated(releaseName string, preLoad int32,
+	client helm.Interface) (created bool, reason string) {
+	if preLoad == revisionUnknown {
+		return false, "revision before the load unknown"
+	}
+	revision, err := installedRevision(releaseName, client)
+	if err != nil {
+		return false, "current revision unknown: " + err.Error()
+	}
+	if revision <= preLoad {
+		return false, "the load didn't change it"
+	}
+	return true, ""
+}
+
+//undoLoad reverts a failed load of the named Release, purging it if it was
+//being installed and the install created it, or rolling it back to the
+//provided revision if it was being upgraded. A failed install of a Release that
+//already existed, e.g. because its name is in use, is left alone. It returns a
+//description of the outcome for the load summary, and whether the Release was
+//purged or rolled back.
+func undoLoad(releaseName string, install bool, revision int32,
+	opts loadOptions, client helm.Interface) (string, bool) {
+	if install {
+		if created, reason := revisionCreated(releaseName, revision,
+			client); !created {
+			return "not purged: " + reason, false
+		}
+		return describeUndo("purged", purgeRelease(releaseName, client))
+	}
+	if revision <= 0 {
+		return "not rolled back: previous revision unknown", false
+	}
+	return describeUndo(fmt.Sprintf("rolled back to revision %d", revision),
+		rollbackRelease(releaseName, revision, opts, client))
+}
+
+//describeUndo returns the outcome of an undo step, for the load summary, and
+//whether it succeeded
+func describeUndo(outcome string, err error) (string, bool) {
+	if err != nil {
+		log.Println("undo of Release failed:", err.Error())
+		return "failed: " + err.Error(), false
+	}
+	return outcome, true
+}
+
+//rollbackRelease rolls the named Release back to the provided revision
+func rollbackRelease(releaseName string, revision int32, opts loadOptions,
+	client helm.Interface) error {
+	log.Println("Rolling back Release:", releaseName, "to revision", revision)
+	_, err := client.RollbackRelease(releaseName,
+		helm.RollbackVersion(revision),
+		helm.RollbackWait(opts.wait),
+		helm.RollbackTimeout(int64(opts.timeout.Seconds())),
+		helm.RollbackDisableHooks(opts.hooks == utils.HooksNone),
+		helm.RollbackRecreate(opts.recreatePods),
+	)
+	return err
+}
+
+//purgeRelease deletes the named Release and its history. A Release that was
+//never created counts as purged.
+func purgeRelease(releaseName string, client helm.Interface) error {
+	log.Println("Purging Release:", releaseName)
+	_, err := client.DeleteRelease(releaseName, deleteOptions()...)
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+//historyClient is a FakeClient whose ReleaseHistory returns only the named
+//Release's revisions, newest first, as Tiller does
+type historyClient struct {
+	*helm.FakeClient
+}
+
+func (c historyClient) ReleaseHistory(rlsName string,
+	opts ...helm.HistoryOption) (*rls.GetHistoryResponse, error) {
+	var history []*release.Release
+	for _, rel := range c.Rels {
+		if rel.GetName() == rlsName {
+			history = append([]*release.Release{rel}, history...)
+		}
+	}
+	return &rls.GetHistoryResponse{Releases: history}, nil
+}
+
+func mockRelease(name string, version int32) *release.Release {
+	return helm.ReleaseMock(&helm.MockReleaseOptions{Name: name,
+		Version: version, Namespace: "default"})
+}
+
+func TestAtomicInstallOfNameInUse(t *testing.T) {
+	atomic, hooks = true, utils.HooksNone
+	defer func() { atomic, hooks = false, "" }()
+	client := historyClient{&helm.FakeClient{
+		Rels: []*release.Release{mockRelease("app", 3)}}}
+	loaded := &release.Release{Name: "app", Namespace: "default",
+		Config: &chart.Config{},
+		Chart:  &chart.Chart{Metadata: &chart.Metadata{Name: "app"}}}
+	result := loadRelease(loaded, true, client)
+	if result.err == nil {
+		t.Fatal("Install of a Release whose name is in use succeeded")
+	}
+	expectedString := "not purged: the load didn't change it"
+	if result.rollback != expectedString {
+		t.Errorf("Undo was incorrect, got: %s, want: %s.", result.rollback,
+			expectedString)
+	}
+	if len(client.Rels) != 1 {
+		t.Error("Release installed before the load was purged")
+	}
+}
+
+func TestUndoLoad(t *testing.T) {
+	opts := loadOptions{hooks: utils.HooksNone}
+	client := historyClient{&helm.FakeClient{}}
+	client.Rels = []*release.Release{mockRelease("created", 1)}
+	for _, c := range []struct {
+		release  string
+		install  bool
+		revision int32
+		expected string
+	}{
+		{"created", true, 0, "purged"},
+		{"unknown", true, revisionUnknown,
+			"not purged: revision before the load unknown"},
+		{"upgraded", false, 2, "rolled back to revision 2"},
+		{"upgraded", false, revisionUnknown,
+			"not rolled back: previous revision unknown"},
+	} {
+		actualString, _ := undoLoad(c.release, c.install, c.revision, opts,
+			client)
+		if actualString != c.expected {
+			t.Errorf("Undo of %s was incorrect, got: %s, want: %s.", c.release,
+				actualString, c.expected)
+		}
+	}
+	if len(client.Rels) != 0 {
+		t.Error("Release created by the failed install wasn't purged")
+	}
+}
+
+func TestPreLoadRevision(t *testing.T) {
+	client := historyClient{&helm.FakeClient{
+		Rels: []*release.Release{mockRelease("app", 1), mockRelease("app", 2)}}}
+	if revision := preLoadRevision("app", false, client); revision != 0 {
+		t.Errorf("Revision recorded without --atomic, got: %d", revision)
+	}
+	atomic = true
+	defer func() { atomic = false }()
+	for releaseName, expected := range map[string]int32{"app": 2, "new": 0} {
+		if revision := preLoadRevision(releaseName, true,
+			client); revision != expected {
+			t.Errorf("Pre-load revision of %s was incorrect, got: %d, want: %d.",
+				releaseName, revision, expected)
+		}
+	}
+}
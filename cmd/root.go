@@ -16,22 +16,32 @@ package cmd
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
+	"github.com/ovotech/helm-bulk/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/helm/pkg/helm"
 )
 
 var cfgFile string
 var local bool
 var disableTLS bool
 var filePrefix string
+var file string
+var latest bool
+var retryPolicy utils.RetryPolicy
 var tlsKey string
 var tlsCert string
 var caCert string
 var tlsServerName string
 
+// Version is the helm-bulk version, set by main
+var Version = "dev"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "helm-bulk",
@@ -42,6 +52,7 @@ var rootCmd = &cobra.Command{
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	rootCmd.Version = Version
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -54,6 +65,10 @@ func init() {
 	loadCmd.Flags().BoolVarP(&disableTLS, "disable-tls", "t", false, "")
 	rootCmd.PersistentFlags().StringVarP(&filePrefix, "fileprefix", "f",
 		"helm-releases", "File prefix to use with a Load or Save command")
+	rootCmd.PersistentFlags().StringVar(&file, "file", "",
+		"Archive location, overriding --fileprefix. Can be a local path, an"+
+			" s3://bucket/key, gs://bucket/key or http(s):// URL, or '-' to"+
+			" write to stdout on save and read from stdin on load/show")
 	rootCmd.PersistentFlags().StringVarP(&tlsKey, "tls-key-path", "k",
 		helmHome+"/key.pem", "Filepath of TLS key")
 	rootCmd.PersistentFlags().StringVarP(&tlsCert, "tls-cert-path", "p",
@@ -62,6 +77,10 @@ func init() {
 		helmHome+"/ca.pem", "Filepath of CA cert")
 	rootCmd.PersistentFlags().StringVarP(&tlsServerName, "tls-server-name", "s",
 		"", "TLS server name")
+	rootCmd.PersistentFlags().IntVar(&retryPolicy.Retries, "retries", 3,
+		"Number of times to retry Tiller calls that fail with transient errors")
+	rootCmd.PersistentFlags().DurationVar(&retryPolicy.Backoff, "retry-backoff",
+		time.Second, "Initial wait between retries, doubling after each retry")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -90,14 +109,53 @@ func initConfig() {
 	}
 }
 
+// newClient returns a Helm client configured from the persistent flags
+func newClient() helm.Interface {
+	if retryPolicy.Backoff < 0 {
+		panic("--retry-backoff can't be negative")
+	}
+	return utils.Client(tlsKey, tlsCert, caCert, tlsServerName, disableTLS,
+		retryPolicy)
+}
+
 // textFilename returns the text filename
 func textFilename() (filename string) {
 	filename = filePrefix + ".txt"
 	return
 }
 
-// archiveFilename returns the archive filename
+// archiveFilename returns the archive filename, which may be a URL
 func archiveFilename() (filename string) {
+	if file != "" {
+		filename = file
+		return
+	}
 	filename = filePrefix + ".tar.gz"
 	return
 }
+
+// archiveStore returns the Store holding the archive, and the archive's name
+// within it
+func archiveStore() (store utils.Store, name string) {
+	store, name, err := utils.NewStore(archiveFilename())
+	utils.PanicCheck(err)
+	return
+}
+
+// streaming returns whether the archive is streamed through stdin/stdout
+func streaming() bool {
+	return archiveFilename() == utils.StdioLocation
+}
+
+// sourceArchive returns the Store and name of the archive to read Releases
+// from, which is the newest timestamped archive if --latest is set
+func sourceArchive() (store utils.Store, name string) {
+	store, name = archiveStore()
+	if latest {
+		var err error
+		name, err = utils.LatestArchive(store, name)
+		utils.PanicCheck(err)
+		log.Println("Using latest archive:", name)
+	}
+	return
+}
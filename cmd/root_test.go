@@ -21,3 +21,12 @@ func TestArchiveFileName(t *testing.T) {
 			actualString, expectedString)
 	}
 }
+
+//panics returns whether f panics
+func panics(f func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	f()
+	return
+}
@@ -16,12 +16,10 @@ package cmd
 
 import (
 	"bytes"
-	"io/ioutil"
 	"log"
-	"os"
 	"strconv"
+	"time"
 
-	"github.com/mholt/archiver"
 	"github.com/ovotech/helm-bulk/utils"
 	"github.com/spf13/cobra"
 	"k8s.io/helm/pkg/helm"
@@ -41,9 +39,13 @@ var (
 		},
 	}
 	orderPrefConfigDir string
+	timestamp          bool
 )
 
 func init() {
+	saveCmd.Flags().BoolVar(&timestamp, "timestamp", false,
+		"Append a UTC timestamp to the archive name, e.g."+
+			" helm-releases-20190509T153000Z.tar.gz")
 	rootCmd.AddCommand(saveCmd)
 	loadCmd.Flags().StringVarP(&orderPrefConfigDir, "order-pref-config-dir", "c", ".",
 		"Path (absolute or relative) of directory containing the orderPref.yaml config")
@@ -64,8 +66,14 @@ func releaseFromName(searchName string,
 
 //targetReleases returns a slice of Releases based on the preferred ordering and
 //those currently installed.
-func targetReleases(releases []*release.Release) (targetReleases []*release.Release) {
-	orderPreferences := utils.OrderPref(orderPrefConfigDir)
+func targetReleases(releases []*release.Release) []*release.Release {
+	return orderReleases(releases, utils.OrderPref(orderPrefConfigDir))
+}
+
+//orderReleases returns the Releases named in the ordering first, in that
+//order, followed by the rest in their existing order
+func orderReleases(releases []*release.Release,
+	orderPreferences []string) (targetReleases []*release.Release) {
 	for _, orderedReleaseName := range orderPreferences {
 		targetRelease := releaseFromName(orderedReleaseName, releases)
 		if targetRelease != nil {
@@ -83,16 +91,27 @@ func targetReleases(releases []*release.Release) (targetReleases []*release.Rele
 //save obtains a slice of deployed releases, base64 encodes each release, adds
 //the base64 string to a buffer, which it then writes to file.
 func save() {
-	client := utils.Client(tlsKey, tlsCert, caCert, tlsServerName, disableTLS)
+	client := newClient()
 	var statusFilter = helm.ReleaseListStatuses([]release.Status_Code{
 		release.Status_DEPLOYED,
 	})
 	releaseResp, err := client.ListReleases(statusFilter)
 	utils.PanicCheck(err)
-	var buffer bytes.Buffer
 	releases := releaseResp.GetReleases()
-	targetReleases := targetReleases(releases)
-	for i, release := range targetReleases {
+	store, name := archiveStore()
+	if timestamp && !streaming() {
+		name = utils.TimestampedArchiveName(name, time.Now())
+	}
+	utils.PanicCheck(store.Put(name, archiveReleases(
+		encodeReleases(targetReleases(releases)), savedMetadata(client))))
+	log.Println("Wrote " + strconv.Itoa(len(releases)) + " Helm Releases to " +
+		name)
+}
+
+//encodeReleases base64 encodes each Release, returning them comma separated
+func encodeReleases(releases []*release.Release) []byte {
+	var buffer bytes.Buffer
+	for i, release := range releases {
 		if i > 0 {
 			buffer.WriteString(",")
 		}
@@ -100,10 +119,30 @@ func save() {
 		utils.PanicCheck(errb)
 		buffer.WriteString(sEnc)
 	}
-	utils.PanicCheck(ioutil.WriteFile(textFilename(), buffer.Bytes(),
-		os.FileMode.Perm(0644)))
-	utils.PanicCheck(archiver.TarGz.Make(archiveFilename(),
-		[]string{textFilename()}))
-	log.Println("Wrote " + strconv.Itoa(len(releases)) + " Helm Releases to file")
-	os.Remove(textFilename())
+	return buffer.Bytes()
+}
+
+//archiveReleases tars and gzips the encoded Releases and metadata in memory,
+//so nothing is written to the working directory
+func archiveReleases(encoded []byte,
+	metadata *utils.ArchiveMetadata) *bytes.Buffer {
+	var archive bytes.Buffer
+	utils.PanicCheck(utils.WriteArchive(&archive, textFilename(), encoded,
+		metadata))
+	return &archive
+}
+
+//savedMetadata returns the metadata of an archive of Releases saved from the
+//Cluster the client's connected to. The Cluster's identified by
+//--cluster-context, or kubectl's current context, and left unrecorded if
+//kubectl can't be run.
+func savedMetadata(client helm.Interface) *utils.ArchiveMetadata {
+	tillerVersion, err := utils.TillerVersion(client)
+	utils.PanicCheck(err)
+	context, err := newCluster().Context()
+	if err != nil {
+		log.Println("WARNING: source Cluster not recorded, kubectl config view"+
+			" failed:", err)
+	}
+	return utils.SavedMetadata(Version, tillerVersion, context)
 }
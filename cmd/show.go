@@ -17,9 +17,12 @@ package cmd
 import (
 	"bytes"
 	"log"
+	"strconv"
 	"strings"
 
+	"github.com/ovotech/helm-bulk/utils"
 	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
 // saveCmd represents the save command
@@ -34,14 +37,19 @@ var showCmd = &cobra.Command{
 }
 
 func init() {
+	showCmd.Flags().BoolVar(&latest, "latest", false,
+		"Show the newest archive written with 'save --timestamp'")
 	rootCmd.AddCommand(showCmd)
 }
 
 //show logs details of Releases it's loaded from file
 func show() {
-	loadedReleases := Releases()
+	loadedReleases, metadata := sourceReleases()
+	log.Println("Saved from Tiller", orUnknown(metadata.GetTillerVersion()),
+		"by helm-bulk", orUnknown(metadata.GetHelmBulkVersion()))
+	logSource(metadata)
 	var buffer bytes.Buffer
-	buffer.WriteString(string(len(loadedReleases)))
+	buffer.WriteString(strconv.Itoa(len(loadedReleases)))
 	buffer.WriteString(" Releases loaded from file:")
 	buffer.WriteString("\n\n")
 	for _, release := range loadedReleases {
@@ -55,7 +63,23 @@ func show() {
 		buffer.WriteString("        values: ")
 		buffer.WriteString(strings.Replace(release.GetConfig().String(),
 			"\\n", "\"\n                    \"", -1))
-		buffer.WriteString("\n\n")
+		buffer.WriteString("\n")
+		addHooksToBuffer(release, &buffer)
+		buffer.WriteString("\n")
 	}
 	log.Println(buffer.String())
 }
+
+//addHooksToBuffer adds a line per hook carried by the Release to the buffer
+func addHooksToBuffer(release *release.Release, buffer *bytes.Buffer) {
+	buffer.WriteString("        hooks:")
+	if len(release.GetHooks()) == 0 {
+		buffer.WriteString(" none")
+	}
+	buffer.WriteString("\n")
+	for _, hook := range release.GetHooks() {
+		buffer.WriteString("            ")
+		buffer.WriteString(utils.HookSummary(hook))
+		buffer.WriteString("\n")
+	}
+}
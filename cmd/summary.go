@@ -0,0 +1,85 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ovotech/helm-bulk/utils"
+)
+
+//loadResult records the outcome of loading a single Release
+type loadResult struct {
+	release  string
+	op       string
+	status   string
+	waited   bool
+	duration time.Duration
+	err      error
+	rollback string
+	//undone is whether --atomic purged or rolled back the Release after it
+	//failed to load
+	undone bool
+}
+
+//skippedStatus is the status of a Release that wasn't loaded
+const skippedStatus = "SKIPPED"
+
+//firstFailure returns the name of the first Release that failed to load, or an
+//empty string if there isn't one
+func firstFailure(results []loadResult) string {
+	for _, result := range results {
+		if result.err != nil {
+			return result.release
+		}
+	}
+	return ""
+}
+
+//notReady returns the name of the first Release that was waited on and didn't
+//become ready in time, or an empty string if there isn't one. Releases that
+//failed for other reasons, e.g. a template error, don't count.
+func notReady(results []loadResult) string {
+	for _, result := range results {
+		if result.waited && utils.WaitTimedOut(result.err) {
+			return result.release
+		}
+	}
+	return ""
+}
+
+//logSummary logs a table of the results of a load, including how long each
+//Release took
+func logSummary(results []loadResult) {
+	var buffer bytes.Buffer
+	addHeaderToBuffer("Load summary:", &buffer)
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "    RELEASE\tOP\tSTATUS\tWAITED\tDURATION\tROLLBACK\tERROR")
+	for _, result := range results {
+		errString := ""
+		if result.err != nil {
+			errString = result.err.Error()
+		}
+		fmt.Fprintf(w, "    %s\t%s\t%s\t%t\t%s\t%s\t%s\n", result.release,
+			result.op, result.status, result.waited,
+			result.duration.Round(time.Second), result.rollback, errString)
+	}
+	w.Flush()
+	log.Println(buffer.String())
+}
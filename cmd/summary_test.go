@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNotReady(t *testing.T) {
+	results := []loadResult{
+		{release: "unwaited", err: errors.New("failed")},
+		{release: "ready", waited: true},
+		{release: "invalid", waited: true,
+			err: errors.New("parse error in \"app/templates/svc.yaml\"")},
+		{release: "crds", waited: true,
+			err: errors.New("release crds failed: timed out waiting for the" +
+				" condition")},
+	}
+	actualString := notReady(results)
+	expectedString := "crds"
+	if actualString != expectedString {
+		t.Errorf("Blocking Release was incorrect, got: %s, want: %s.",
+			actualString, expectedString)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+var (
+	templateCmd = &cobra.Command{
+		Use:   "template",
+		Short: "Render a Release's manifest from File without a Cluster",
+		Long: `This command will render the chart saved with a Release in File,
+	 with its saved values and any given by --values, and print the manifest.
+	 With --compare, it instead prints how the fresh render differs from the
+	 manifest saved with the Release, exiting non-zero if it does.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			log.Println("helm-bulk template called")
+			template(namedRelease(templateRelease, Releases()))
+		},
+	}
+	templateRelease     string
+	templateValues      []string
+	templateKubeVersion string
+	templateCompare     bool
+)
+
+func init() {
+	templateCmd.Flags().StringVar(&templateRelease, "release", "",
+		"Name of the Release to render")
+	templateCmd.Flags().StringArrayVar(&templateValues, "values", nil,
+		"Values file overriding the saved values. Can be repeated, with later"+
+			" files taking precedence")
+	templateCmd.Flags().StringVar(&templateKubeVersion, "kube-version", "",
+		"Kubernetes version reported to templates, e.g. 1.14")
+	templateCmd.Flags().BoolVar(&templateCompare, "compare", false,
+		"Diff the fresh render against the manifest saved with the Release")
+	templateCmd.Flags().BoolVar(&latest, "latest", false,
+		"Render from the newest archive written with 'save --timestamp'")
+	rootCmd.AddCommand(templateCmd)
+}
+
+//template prints the Release's freshly rendered manifest, or with --compare,
+//its differences from the saved manifest
+func template(release *release.Release) {
+	overrides := ""
+	if len(templateValues) > 0 {
+		var err error
+		overrides, err = utils.ReadValues(templateValues)
+		utils.PanicCheck(err)
+	}
+	manifest, err := utils.RenderRelease(release, overrides,
+		templateKubeVersion)
+	utils.PanicCheck(err)
+	if !templateCompare {
+		fmt.Print(manifest)
+		return
+	}
+	diff, err := utils.ManifestDiff(release.GetManifest(), manifest)
+	utils.PanicCheck(err)
+	if diff == "" {
+		log.Println("Release:", release.GetName(), "renders the same as its"+
+			" saved manifest")
+		return
+	}
+	fmt.Print(diff)
+	log.Println("Release:", release.GetName(), "renders differently from its"+
+		" saved manifest")
+	os.Exit(1)
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"text/tabwriter"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//compensationStep records an action taken to undo part of a failed
+//transactional load
+type compensationStep struct {
+	release string
+	action  string
+	outcome string
+}
+
+//recordPreLoadRevisions returns the current revision of each of the Releases
+//to be loaded, keyed by Release name, when loading with --transaction; 0 for
+//those to be installed that aren't. It panics if any can't be obtained, as the
+//transaction couldn't then be undone.
+func recordPreLoadRevisions(installReleases, updateReleases []*release.Release,
+	client helm.Interface) (revisions map[string]int32) {
+	revisions = map[string]int32{}
+	if !transaction {
+		return
+	}
+	for _, release := range installReleases {
+		revision, err := installedRevision(release.GetName(), client)
+		utils.PanicCheck(err)
+		revisions[release.GetName()] = revision
+	}
+	for _, release := range updateReleases {
+		revision, err := currentRevision(release.GetName(), client)
+		utils.PanicCheck(err)
+		revisions[release.GetName()] = revision
+	}
+	return
+}
+
+//compensate undoes a failed transactional load in reverse order, purging each
+//Release that was installed and rolling back each Release that was upgraded to
+//its pre-load revision, then logs a report of the steps taken. Releases that
+//were skipped, or already undone by --atomic, are left alone.
+func compensate(results []loadResult, revisions map[string]int32,
+	client helm.Interface) {
+	log.Println("Transaction failed, undoing load")
+	logCompensation(compensationSteps(results, revisions, client))
+}
+
+//compensationSteps undoes the loads of the Releases in reverse order, returning
+//the steps taken
+func compensationSteps(results []loadResult, revisions map[string]int32,
+	client helm.Interface) (steps []compensationStep) {
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i].status != skippedStatus && !results[i].undone {
+			steps = append(steps, compensateRelease(results[i], revisions, client))
+		}
+	}
+	return
+}
+
+//compensateRelease undoes the load of a single Release. A Release that failed
+//to load is only undone if the attempt changed it, so a failed install of a
+//Release that already existed isn't purged.
+func compensateRelease(result loadResult, revisions map[string]int32,
+	client helm.Interface) (step compensationStep) {
+	step.release = result.release
+	if result.err != nil {
+		if created, reason := revisionCreated(result.release,
+			revisions[result.release], client); !created {
+			step.action, step.outcome = "none", reason
+			return
+		}
+	}
+	var err error
+	if result.op == opString(true) {
+		step.action = "purge"
+		err = purgeRelease(result.release, client)
+	} else {
+		revision := revisions[result.release]
+		step.action = fmt.Sprintf("rollback to revision %d", revision)
+		err = rollbackRelease(result.release, revision,
+			releaseLoadOptions(result.release), client)
+	}
+	step.outcome = "OK"
+	if err != nil {
+		step.outcome = "FAILED: " + err.Error()
+	}
+	return
+}
+
+//logCompensation logs a table of the steps taken to undo a failed transaction
+func logCompensation(steps []compensationStep) {
+	var buffer bytes.Buffer
+	addHeaderToBuffer("Transaction compensation report:", &buffer)
+	w := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "    RELEASE\tACTION\tOUTCOME")
+	for _, step := range steps {
+		fmt.Fprintf(w, "    %s\t%s\t%s\n", step.release, step.action, step.outcome)
+	}
+	w.Flush()
+	log.Println(buffer.String())
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestCompensationSteps(t *testing.T) {
+	hooks = utils.HooksNone
+	defer func() { hooks = "" }()
+	client := historyClient{&helm.FakeClient{Rels: []*release.Release{
+		mockRelease("installed", 1), mockRelease("inuse", 3),
+		mockRelease("upgraded", 2), mockRelease("upgraded", 3),
+		mockRelease("undone", 1)}}}
+	revisions := map[string]int32{"installed": 0, "inuse": 3, "upgraded": 2,
+		"undone": 0}
+	failed := errors.New("failed")
+	results := []loadResult{
+		{release: "installed", op: "install"},
+		{release: "inuse", op: "install", err: failed},
+		{release: "undone", op: "install", err: failed, undone: true},
+		{release: "upgraded", op: "upgrade"},
+		{release: "later", op: "upgrade", status: skippedStatus, err: failed},
+	}
+	expected := []compensationStep{
+		{"upgraded", "rollback to revision 2", "OK"},
+		{"inuse", "none", "the load didn't change it"},
+		{"installed", "purge", "OK"},
+	}
+	steps := compensationSteps(results, revisions, client)
+	if len(steps) != len(expected) {
+		t.Fatalf("Incorrect compensation steps, got: %+v, want: %+v.", steps,
+			expected)
+	}
+	for i := range steps {
+		if steps[i] != expected[i] {
+			t.Errorf("Incorrect compensation step, got: %+v, want: %+v.",
+				steps[i], expected[i])
+		}
+	}
+	if utils.ReleaseIndex("inuse", client.Rels) < 0 {
+		t.Error("Release installed before the load was purged")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+)
+
+var versionCheck string
+
+func init() {
+	loadCmd.Flags().StringVar(&versionCheck, "version-check",
+		utils.VersionCheckWarn, "Whether to warn or refuse when File was saved"+
+			" from an incompatible Tiller, or by a newer helm-bulk: warn or refuse")
+}
+
+//checkVersions logs the versions File was saved with and the target Tiller
+//version, warning, or panicking with --version-check refuse, if they're not
+//compatible
+func checkVersions(metadata *utils.ArchiveMetadata, client helm.Interface) {
+	if !utils.ValidVersionCheck(versionCheck) {
+		panic("Unknown version check '" + versionCheck + "', must be " +
+			utils.VersionCheckWarn + " or " + utils.VersionCheckRefuse)
+	}
+	tillerVersion, err := utils.TillerVersion(client)
+	utils.PanicCheck(err)
+	log.Println("Saved from Tiller", orUnknown(metadata.GetTillerVersion()),
+		"by helm-bulk", orUnknown(metadata.GetHelmBulkVersion()))
+	log.Println("Loading into Tiller", tillerVersion, "with helm-bulk", Version)
+	problems := utils.CompatibilityProblems(metadata, tillerVersion, Version)
+	for _, problem := range problems {
+		log.Println("WARNING:", problem)
+	}
+	if len(problems) > 0 && versionCheck == utils.VersionCheckRefuse {
+		panic("File may not be compatible, refusing to load with" +
+			" --version-check " + utils.VersionCheckRefuse)
+	}
+}
+
+//orUnknown returns the metadata value, or unknown if it wasn't recorded, as in
+//archives saved before it was
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
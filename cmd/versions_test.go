@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ovotech/helm-bulk/utils"
+	"k8s.io/helm/pkg/helm"
+)
+
+func TestCheckVersions(t *testing.T) {
+	defer func() { versionCheck = utils.VersionCheckWarn }()
+	incompatible := &utils.ArchiveMetadata{TillerVersion: "v2.13.1"}
+	versionCheck = utils.VersionCheckWarn
+	if panics(func() { checkVersions(incompatible, &helm.FakeClient{}) }) {
+		t.Error("Version check refused an incompatible archive with warn")
+	}
+	versionCheck = utils.VersionCheckRefuse
+	if !panics(func() { checkVersions(incompatible, &helm.FakeClient{}) }) {
+		t.Error("Version check loaded an incompatible archive with refuse")
+	}
+	if panics(func() { checkVersions(nil, &helm.FakeClient{}) }) {
+		t.Error("Version check refused an archive without metadata")
+	}
+	compatible := &utils.ArchiveMetadata{
+		TillerVersion: "1.2.3-fakeclient+testonly"}
+	if panics(func() { checkVersions(compatible, &helm.FakeClient{}) }) {
+		t.Error("Version check refused a compatible archive")
+	}
+}
@@ -18,6 +18,10 @@ import (
 	"github.com/ovotech/helm-bulk/cmd"
 )
 
+//version is set at build time by goreleaser
+var version = "dev"
+
 func main() {
+	cmd.Version = version
 	cmd.Execute()
 }
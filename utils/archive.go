@@ -0,0 +1,190 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"time"
+)
+
+//MetadataFilename is the name of the file in an archive recording where and how
+//it was saved
+const MetadataFilename = "metadata.json"
+
+//ArchiveMetadata records where and how an archive was saved. It's absent from
+//archives written before it was introduced.
+type ArchiveMetadata struct {
+	//HelmBulkVersion is the version of helm-bulk that wrote the archive
+	HelmBulkVersion string `json:"helmBulkVersion"`
+	//TillerVersion is the version of the Tiller the Releases were saved from
+	TillerVersion string `json:"tillerVersion,omitempty"`
+	//KubeContext is the name of the kubeconfig context the Releases were saved
+	//from
+	KubeContext string `json:"kubeContext,omitempty"`
+	//Server is the API server URL of the Cluster the Releases were saved from
+	Server string `json:"server,omitempty"`
+	//TillerNamespace is the namespace of the Tiller the Releases were saved from
+	TillerNamespace string `json:"tillerNamespace,omitempty"`
+	//User is the local user who saved the Releases
+	User string `json:"user,omitempty"`
+	//SavedAt is when the Releases were saved, in RFC 3339 format
+	SavedAt string `json:"savedAt,omitempty"`
+}
+
+//WriteArchive writes a gzipped tarball to w, containing a file with the
+//provided name and data, preceded by the metadata unless it's nil. Archives
+//are built and read entirely in memory, so concurrent runs in the same
+//directory can't clobber each other's files, and plaintext Release data is
+//never left on disk.
+func WriteArchive(w io.Writer, name string, data []byte,
+	metadata *ArchiveMetadata) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	if metadata != nil {
+		encoded, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = writeTarFile(tw, MetadataFilename, encoded); err != nil {
+			return err
+		}
+	}
+	if err := writeTarFile(tw, name, data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+//writeTarFile writes a file with the provided name and data to the tarball
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+//ReadArchive reads a gzipped tarball from r, returning the contents of the
+//first .txt file it contains, and its metadata, which is nil if absent
+func ReadArchive(r io.Reader) (data []byte, metadata *ArchiveMetadata,
+	err error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gzr.Close()
+	files, err := readTarFiles(tar.NewReader(gzr))
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = findTextFile(files); err != nil {
+		return nil, nil, err
+	}
+	metadata, err = findMetadata(files)
+	return data, metadata, err
+}
+
+//tarFile is a file read from a tarball
+type tarFile struct {
+	name string
+	data []byte
+}
+
+//readTarFiles reads every file in the tarball
+func readTarFiles(tr *tar.Reader) (files []tarFile, err error) {
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, tarFile{name: header.Name, data: data})
+	}
+}
+
+//findTextFile returns the contents of the first .txt file
+func findTextFile(files []tarFile) ([]byte, error) {
+	for _, file := range files {
+		if path.Ext(file.name) == ".txt" {
+			return file.data, nil
+		}
+	}
+	return nil, errors.New("no .txt file found in archive")
+}
+
+//findMetadata decodes the metadata file, returning nil if there isn't one
+func findMetadata(files []tarFile) (*ArchiveMetadata, error) {
+	for _, file := range files {
+		if file.name == MetadataFilename {
+			metadata := &ArchiveMetadata{}
+			return metadata, json.Unmarshal(file.data, metadata)
+		}
+	}
+	return nil, nil
+}
+
+//SuffixedArchiveName inserts the suffix into the provided archive name, before
+//the extension, e.g. helm-releases-kube-system.tar.gz
+func SuffixedArchiveName(name, suffix string) string {
+	return archiveStem(name) + "-" + suffix + archiveExtension
+}
+
+//GetTillerVersion returns the source Tiller version, or empty if there's no
+//metadata
+func (m *ArchiveMetadata) GetTillerVersion() string {
+	if m == nil {
+		return ""
+	}
+	return m.TillerVersion
+}
+
+//GetHelmBulkVersion returns the helm-bulk version that wrote the archive, or
+//empty if there's no metadata
+func (m *ArchiveMetadata) GetHelmBulkVersion() string {
+	if m == nil {
+		return ""
+	}
+	return m.HelmBulkVersion
+}
+
+//GetServer returns the API server URL of the source Cluster, or empty if
+//there's no metadata
+func (m *ArchiveMetadata) GetServer() string {
+	if m == nil {
+		return ""
+	}
+	return m.Server
+}
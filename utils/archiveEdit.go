@@ -0,0 +1,66 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//Merge conflict policies, selecting which Release is kept when both archives
+//being merged hold a Release of the same name
+const (
+	ConflictFail   = "fail"
+	ConflictFirst  = "first"
+	ConflictSecond = "second"
+)
+
+//MergeReleases returns the Releases in first followed by those in second,
+//resolving Releases of the same name in both by the conflict policy. A Release
+//kept from second replaces the one in first, in its position.
+func MergeReleases(first, second []*release.Release,
+	conflict string) ([]*release.Release, error) {
+	merged := append([]*release.Release{}, first...)
+	for _, rel := range second {
+		i := ReleaseIndex(rel.GetName(), merged)
+		switch {
+		case i < 0:
+			merged = append(merged, rel)
+		case conflict == ConflictFirst:
+		case conflict == ConflictSecond:
+			merged[i] = rel
+		case conflict == ConflictFail:
+			return nil, fmt.Errorf("Release %s is in both archives", rel.GetName())
+		default:
+			return nil, fmt.Errorf("unknown conflict policy: %s", conflict)
+		}
+	}
+	return merged, nil
+}
+
+//GroupByNamespace groups the Releases by namespace, preserving their order,
+//and returns the namespaces in the order they first appear
+func GroupByNamespace(releases []*release.Release) (namespaces []string,
+	groups map[string][]*release.Release) {
+	groups = map[string][]*release.Release{}
+	for _, rel := range releases {
+		if _, ok := groups[rel.GetNamespace()]; !ok {
+			namespaces = append(namespaces, rel.GetNamespace())
+		}
+		groups[rel.GetNamespace()] = append(groups[rel.GetNamespace()], rel)
+	}
+	return
+}
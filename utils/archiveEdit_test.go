@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestMergeReleases(t *testing.T) {
+	first := []*release.Release{{Name: "app", Version: 1}, {Name: "db"}}
+	second := []*release.Release{{Name: "app", Version: 2}, {Name: "cache"}}
+	if _, err := MergeReleases(first, second, ConflictFail); err == nil {
+		t.Error("No error returned for a conflict with the fail policy")
+	}
+	merged, err := MergeReleases(first, second, ConflictSecond)
+	if err != nil || len(merged) != 3 || merged[0].GetVersion() != 2 ||
+		merged[2].GetName() != "cache" {
+		t.Errorf("Incorrect merge with the second policy, got: %v, %v.", merged,
+			err)
+	}
+	merged, err = MergeReleases(first, second, ConflictFirst)
+	if err != nil || len(merged) != 3 || merged[0].GetVersion() != 1 {
+		t.Errorf("Incorrect merge with the first policy, got: %v, %v.", merged,
+			err)
+	}
+	if first[0].GetVersion() != 1 {
+		t.Error("First archive's Releases were modified by the merge")
+	}
+}
+
+func TestGroupByNamespace(t *testing.T) {
+	releases := []*release.Release{
+		{Name: "app", Namespace: "web"},
+		{Name: "db", Namespace: "data"},
+		{Name: "cache", Namespace: "web"},
+	}
+	namespaces, groups := GroupByNamespace(releases)
+	if len(namespaces) != 2 || namespaces[0] != "web" ||
+		len(groups["web"]) != 2 || groups["web"][1].GetName() != "cache" {
+		t.Errorf("Incorrect groups returned, got: %v, %v.", namespaces, groups)
+	}
+	name := SuffixedArchiveName("backups/helm-releases.tar.gz", "web")
+	if name != "backups/helm-releases-web.tar.gz" {
+		t.Errorf("Incorrect archive name, got: %s, want: %s.", name,
+			"backups/helm-releases-web.tar.gz")
+	}
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	var archive bytes.Buffer
+	if err := WriteArchive(&archive, "helm-releases.txt", []byte("a,b"),
+		nil); err != nil {
+		t.Fatal("Error writing archive", err)
+	}
+	actual, metadata, err := ReadArchive(&archive)
+	if err != nil {
+		t.Fatal("Error reading archive", err)
+	}
+	if string(actual) != "a,b" {
+		t.Errorf("Archive contents were incorrect, got: %s, want: %s.",
+			actual, "a,b")
+	}
+	if metadata != nil {
+		t.Errorf("Metadata was read from an archive without it, got: %+v",
+			metadata)
+	}
+}
+
+func TestArchiveMetadataRoundTrip(t *testing.T) {
+	var archive bytes.Buffer
+	written := &ArchiveMetadata{HelmBulkVersion: "0.0.28",
+		TillerVersion: "v2.13.1"}
+	if err := WriteArchive(&archive, "helm-releases.txt", []byte("a,b"),
+		written); err != nil {
+		t.Fatal("Error writing archive", err)
+	}
+	actual, metadata, err := ReadArchive(&archive)
+	if err != nil {
+		t.Fatal("Error reading archive", err)
+	}
+	if string(actual) != "a,b" || metadata == nil || *metadata != *written {
+		t.Errorf("Archive contents were incorrect, got: %s, %+v.", actual,
+			metadata)
+	}
+}
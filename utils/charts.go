@@ -0,0 +1,59 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//ParseChartOverrides parses release=path chart overrides into a map of
+//Release name to chart path
+func ParseChartOverrides(specs []string) (map[string]string, error) {
+	overrides := map[string]string{}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid chart override '%s', must be"+
+				" release=path", spec)
+		}
+		overrides[parts[0]] = parts[1]
+	}
+	return overrides, nil
+}
+
+//FindChart returns the path of a chart in dir for the Release, being a chart
+//directory or .tgz named after the Release, or failing that after its chart,
+//or "" if there's none
+func FindChart(dir string, rel *release.Release) string {
+	metadata := rel.GetChart().GetMetadata()
+	for _, name := range []string{
+		rel.GetName(),
+		rel.GetName() + ".tgz",
+		metadata.GetName() + "-" + metadata.GetVersion() + ".tgz",
+		metadata.GetName(),
+		metadata.GetName() + ".tgz",
+	} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestParseChartOverrides(t *testing.T) {
+	overrides, err := ParseChartOverrides([]string{"app=charts/app-1.2.tgz"})
+	if err != nil || overrides["app"] != "charts/app-1.2.tgz" {
+		t.Errorf("Incorrect overrides returned, got: %v, %v.", overrides, err)
+	}
+	for _, spec := range []string{"app", "=charts/app", "app="} {
+		if _, err := ParseChartOverrides([]string{spec}); err == nil {
+			t.Errorf("No error returned for chart override: %s", spec)
+		}
+	}
+}
+
+func TestFindChart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-bulk-charts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	rel := &release.Release{Name: "app-prod", Chart: &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app", Version: "1.2.0"}}}
+	if path := FindChart(dir, rel); path != "" {
+		t.Errorf("Chart found in empty dir: %s", path)
+	}
+	chartDir := filepath.Join(dir, "app")
+	if err := os.Mkdir(chartDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(chartDir, "Chart.yaml"),
+		[]byte("apiVersion: v1\nname: app\nversion: 1.3.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := FindChart(dir, rel)
+	if path != chartDir {
+		t.Errorf("Incorrect chart path returned, got: %s, want: %s.", path,
+			chartDir)
+	}
+}
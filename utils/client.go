@@ -22,8 +22,10 @@ import (
 	"k8s.io/helm/pkg/tlsutil"
 )
 
-//Client creates a Helm client and checks the connection works
-func Client(tlsKey, tlsCert, caCert, tlsServerName string, disableTLS bool) (client *helm.Client) {
+//Client creates a Helm client, which retries calls failing with transient
+//errors according to the provided policy, and checks the connection works
+func Client(tlsKey, tlsCert, caCert, tlsServerName string, disableTLS bool,
+	retryPolicy RetryPolicy) (client helm.Interface) {
 	options := []helm.Option{
 		helm.Host(os.Getenv("TILLER_HOST")),
 	}
@@ -44,10 +46,17 @@ func Client(tlsKey, tlsCert, caCert, tlsServerName string, disableTLS bool) (cli
 		options = append(options, helm.WithTLS(tlsCfg))
 	}
 
-	client = helm.NewClient(options...)
+	client = WithRetries(helm.NewClient(options...), retryPolicy)
 	log.Println("Checking Helm client connection")
-	_, errb := client.GetVersion()
+	tillerVersion, errb := TillerVersion(client)
 	PanicCheck(errb)
-	log.Println("Connection: OK")
+	log.Println("Connection: OK, Tiller version:", tillerVersion)
 	return
 }
+
+//TillerVersion returns the semantic version of the Tiller the client's
+//connected to
+func TillerVersion(client helm.Interface) (string, error) {
+	resp, err := client.GetVersion()
+	return resp.GetVersion().GetSemVer(), err
+}
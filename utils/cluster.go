@@ -0,0 +1,149 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+//Cluster answers questions about a Kubernetes Cluster that Tiller can't
+type Cluster interface {
+	Namespaces() (map[string]bool, error)
+	Kinds(apiVersion string) (map[string]bool, error)
+	Context() (ClusterContext, error)
+}
+
+//ClusterContext identifies a Cluster by the kubeconfig context used to reach it
+//and its API server URL. Context names are local to a kubeconfig, so only the
+//server identifies the Cluster itself.
+type ClusterContext struct {
+	Name   string
+	Server string
+}
+
+//NewCluster returns a Cluster that calls the API server through kubectl get
+//--raw, using the kubeconfig context, or kubectl's current context if it's
+//empty
+func NewCluster(context string) Cluster {
+	kubectl := func(args ...string) ([]byte, error) {
+		if context != "" {
+			args = append([]string{"--context", context}, args...)
+		}
+		return exec.Command("kubectl", args...).Output()
+	}
+	return kubectlCluster{
+		context: context,
+		getRaw: func(path string) ([]byte, error) {
+			return kubectl("get", "--raw", path)
+		},
+		viewConfig: func() ([]byte, error) {
+			return kubectl("config", "view", "--minify", "-o", "json")
+		},
+	}
+}
+
+//kubectlCluster is a Cluster backed by raw API server responses
+type kubectlCluster struct {
+	//context is the kubeconfig context used, or empty for the current context
+	context string
+	//getRaw returns the response from a path of the API server
+	getRaw func(path string) ([]byte, error)
+	//viewConfig returns the kubeconfig, minified to the context used
+	viewConfig func() ([]byte, error)
+}
+
+//Namespaces returns the names of the Cluster's namespaces
+func (c kubectlCluster) Namespaces() (map[string]bool, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := c.getJSON("/api/v1/namespaces", &list); err != nil {
+		return nil, err
+	}
+	namespaces := map[string]bool{}
+	for _, item := range list.Items {
+		namespaces[item.Metadata.Name] = true
+	}
+	return namespaces, nil
+}
+
+//Kinds returns the kinds the Cluster serves for the apiVersion, which is none
+//if it doesn't serve the apiVersion at all
+func (c kubectlCluster) Kinds(apiVersion string) (map[string]bool, error) {
+	path := "/apis/" + apiVersion
+	if !strings.Contains(apiVersion, "/") {
+		path = "/api/" + apiVersion
+	}
+	var list struct {
+		Resources []struct {
+			Kind string `json:"kind"`
+		} `json:"resources"`
+	}
+	kinds := map[string]bool{}
+	if err := c.getJSON(path, &list); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok &&
+			strings.Contains(string(exitErr.Stderr), "NotFound") {
+			return kinds, nil
+		}
+		return nil, err
+	}
+	for _, resource := range list.Resources {
+		kinds[resource.Kind] = true
+	}
+	return kinds, nil
+}
+
+//Context returns the context used, and the server of its Cluster
+func (c kubectlCluster) Context() (ClusterContext, error) {
+	data, err := c.viewConfig()
+	if err != nil {
+		return ClusterContext{}, err
+	}
+	var config struct {
+		CurrentContext string `json:"current-context"`
+		Clusters       []struct {
+			Cluster struct {
+				Server string `json:"server"`
+			} `json:"cluster"`
+		} `json:"clusters"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ClusterContext{}, err
+	}
+	if len(config.Clusters) != 1 {
+		return ClusterContext{}, errors.New("kubectl has no current context")
+	}
+	if c.context != "" {
+		config.CurrentContext = c.context
+	}
+	return ClusterContext{Name: config.CurrentContext,
+		Server: config.Clusters[0].Cluster.Server}, nil
+}
+
+//getJSON decodes the JSON response from a path of the API server
+func (c kubectlCluster) getJSON(path string, v interface{}) error {
+	data, err := c.getRaw(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
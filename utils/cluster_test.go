@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKubectlCluster(t *testing.T) {
+	cluster := kubectlCluster{getRaw: func(path string) ([]byte, error) {
+		switch path {
+		case "/api/v1/namespaces":
+			return []byte(`{"items":[{"metadata":{"name":"web"}}]}`), nil
+		case "/apis/apps/v1":
+			return []byte(`{"resources":[{"kind":"Deployment"}]}`), nil
+		}
+		return nil, errors.New("unexpected path: " + path)
+	}}
+	namespaces, err := cluster.Namespaces()
+	if err != nil || !namespaces["web"] || len(namespaces) != 1 {
+		t.Errorf("Incorrect namespaces, got: %v, %v.", namespaces, err)
+	}
+	kinds, err := cluster.Kinds("apps/v1")
+	if err != nil || !kinds["Deployment"] {
+		t.Errorf("Incorrect kinds, got: %v, %v.", kinds, err)
+	}
+	if _, err := cluster.Kinds("v1"); err == nil ||
+		err.Error() != "unexpected path: /api/v1" {
+		t.Errorf("Core API not requested from /api/v1, got: %v", err)
+	}
+}
+
+func TestKubectlClusterContext(t *testing.T) {
+	cluster := kubectlCluster{viewConfig: func() ([]byte, error) {
+		return []byte(`{"current-context":"staging","clusters":[` +
+			`{"name":"staging","cluster":{"server":"https://staging:6443"}}]}`), nil
+	}}
+	context, err := cluster.Context()
+	if err != nil || context.Name != "staging" ||
+		context.Server != "https://staging:6443" {
+		t.Errorf("Incorrect context, got: %+v, %v.", context, err)
+	}
+	cluster.context = "prod"
+	if context, err := cluster.Context(); err != nil || context.Name != "prod" {
+		t.Errorf("Incorrect context for --cluster-context, got: %+v, %v.",
+			context, err)
+	}
+	cluster.viewConfig = func() ([]byte, error) {
+		return []byte(`{"current-context":"","clusters":[]}`), nil
+	}
+	if _, err := cluster.Context(); err == nil {
+		t.Error("No error without a current context")
+	}
+}
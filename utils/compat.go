@@ -0,0 +1,72 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+	"k8s.io/helm/pkg/version"
+)
+
+//Version check modes, selecting whether load warns or refuses when an archive
+//may not be compatible with the target Tiller or this helm-bulk
+const (
+	VersionCheckWarn   = "warn"
+	VersionCheckRefuse = "refuse"
+)
+
+//ValidVersionCheck returns whether the provided string is a version check mode
+func ValidVersionCheck(mode string) bool {
+	return mode == VersionCheckWarn || mode == VersionCheckRefuse
+}
+
+//CompatibilityProblems returns the reasons an archive with the metadata may not
+//load correctly into the target Tiller version using this helm-bulk version.
+//Tiller is compatible, as in helm version.IsCompatible, if it has the same
+//major version as the source Tiller and at least its minor version, whatever
+//the patch versions; a pre-release is only compatible with itself.
+func CompatibilityProblems(metadata *ArchiveMetadata, tillerVersion,
+	helmBulkVersion string) (problems []string) {
+	if metadata == nil {
+		return nil
+	}
+	if metadata.TillerVersion != "" &&
+		!version.IsCompatible(metadata.TillerVersion, tillerVersion) {
+		problems = append(problems, fmt.Sprintf("Releases were saved from"+
+			" Tiller %s, which isn't compatible with Tiller %s",
+			metadata.TillerVersion, tillerVersion))
+	}
+	if newerVersion(metadata.HelmBulkVersion, helmBulkVersion) {
+		problems = append(problems, fmt.Sprintf("archive was written by"+
+			" helm-bulk %s, which is newer than this helm-bulk %s",
+			metadata.HelmBulkVersion, helmBulkVersion))
+	}
+	return
+}
+
+//newerVersion returns whether semantic version a is newer than b, which is
+//false if either can't be parsed, e.g. for development builds
+func newerVersion(a, b string) bool {
+	av, err := semver.NewVersion(a)
+	if err != nil {
+		return false
+	}
+	bv, err := semver.NewVersion(b)
+	if err != nil {
+		return false
+	}
+	return av.GreaterThan(bv)
+}
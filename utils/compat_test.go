@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestCompatibilityProblems(t *testing.T) {
+	metadata := &ArchiveMetadata{HelmBulkVersion: "0.0.28",
+		TillerVersion: "v2.13.1"}
+	for _, c := range []struct {
+		tiller, helmBulk string
+		problems         int
+	}{
+		{"v2.13.0", "0.0.28", 0},
+		{"v2.14.3", "0.0.30", 0},
+		{"v2.12.3", "0.0.28", 1},
+		{"v3.0.0", "0.0.28", 1},
+		{"v2.13.1", "0.0.27", 1},
+		{"v2.13.1", "dev", 0},
+	} {
+		problems := CompatibilityProblems(metadata, c.tiller, c.helmBulk)
+		if len(problems) != c.problems {
+			t.Errorf("Incorrect problems for Tiller %s, helm-bulk %s, got: %v,"+
+				" want: %d.", c.tiller, c.helmBulk, problems, c.problems)
+		}
+	}
+	if problems := CompatibilityProblems(nil, "v2.13.1", "0.0.28"); problems != nil {
+		t.Errorf("Problems found without metadata, got: %v", problems)
+	}
+}
@@ -16,12 +16,27 @@ package utils
 
 import (
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type config struct {
-	Order []string
+	Order        []string
+	Releases     map[string]ReleasePref
+	StatusPolicy map[string]string `mapstructure:"status-policy"`
+}
+
+//ReleasePref holds per-Release overrides of load flags, as defined under the
+//releases key of the config, keyed by Release name
+type ReleasePref struct {
+	Wait         *bool
+	Timeout      time.Duration
+	Hooks        string
+	Force        *bool
+	ReuseValues  *bool `mapstructure:"reuse-values"`
+	ResetValues  *bool `mapstructure:"reset-values"`
+	RecreatePods *bool `mapstructure:"recreate-pods"`
 }
 
 const (
@@ -32,16 +47,50 @@ const (
 //OrderPref returns a slice containing the preferred ordering of Release names.
 // If it doesn't find any defined, it returns an empty slice.
 func OrderPref(configDir string) (releaseOrderPref []string) {
+	releaseOrderPref = readConfig(configDir).Order
+	return
+}
+
+//OrderFile returns the ordering of Release names in the provided config file,
+//which is in the same format as orderPref.yaml
+func OrderFile(file string) ([]string, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return v.GetStringSlice("order"), nil
+}
+
+//ReleasePrefs returns the per-Release load preferences keyed by Release name.
+// If it doesn't find any defined, it returns an empty map.
+func ReleasePrefs(configDir string) (releasePrefs map[string]ReleasePref) {
+	releasePrefs = readConfig(configDir).Releases
+	if releasePrefs == nil {
+		releasePrefs = map[string]ReleasePref{}
+	}
+	return
+}
+
+//StatusPolicyPrefs returns overrides of the default action taken for each
+//installed Release status, keyed by status name.
+// If it doesn't find any defined, it returns nil.
+func StatusPolicyPrefs(configDir string) (statusPolicyPrefs map[string]string) {
+	statusPolicyPrefs = readConfig(configDir).StatusPolicy
+	return
+}
+
+//readConfig reads the orderPref config from the provided directory and the
+//environment
+func readConfig(configDir string) (c config) {
 	viper.AutomaticEnv()
 	viper.SetEnvPrefix(envPrefix)
 	viper.SetConfigName(prefFilename)
 	viper.AddConfigPath(configDir)
 	viper.ReadInConfig()
-	var c config
 	err := viper.Unmarshal(&c)
 	if err != nil {
 		log.Println(err)
 	}
-	releaseOrderPref = c.Order
 	return
 }
@@ -0,0 +1,106 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"strings"
+)
+
+//ManifestDiff compares the resources in two manifests, matched by kind and
+//name, returning a line diff of each resource that's been removed, added or
+//changed, or "" if there are no differences
+func ManifestDiff(from, to string) (string, error) {
+	fromResources, err := ManifestResources(from)
+	if err != nil {
+		return "", err
+	}
+	toResources, err := ManifestResources(to)
+	if err != nil {
+		return "", err
+	}
+	toDocs := map[string]string{}
+	for _, resource := range toResources {
+		toDocs[resourceKey(resource)] = resource.Manifest
+	}
+	var diff bytes.Buffer
+	for _, resource := range fromResources {
+		key := resourceKey(resource)
+		toDoc, ok := toDocs[key]
+		delete(toDocs, key)
+		if !ok || toDoc != resource.Manifest {
+			diff.WriteString("=== " + key + "\n")
+			diff.WriteString(diffLines(resource.Manifest, toDoc))
+		}
+	}
+	for _, resource := range toResources {
+		if _, ok := toDocs[resourceKey(resource)]; ok {
+			diff.WriteString("=== " + resourceKey(resource) + "\n")
+			diff.WriteString(diffLines("", resource.Manifest))
+		}
+	}
+	return diff.String(), nil
+}
+
+//resourceKey identifies a resource by its kind and name
+func resourceKey(resource Resource) string {
+	return resource.Kind + "/" + resource.Metadata.Name
+}
+
+//diffLines returns a line diff between two documents, prefixing lines only in
+//from with "-", lines only in to with "+", and lines in both with " "
+func diffLines(from, to string) string {
+	a, b := splitLines(from), splitLines(to)
+	//lcs[i][j] is the length of the longest common subsequence of a[i:], b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var diff bytes.Buffer
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff.WriteString(" " + a[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+	return diff.String()
+}
+
+//splitLines splits a document into lines, returning none for an empty document
+func splitLines(doc string) []string {
+	if doc == "" {
+		return nil
+	}
+	return strings.Split(doc, "\n")
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//Export formats
+const (
+	ExportHelmfile  = "helmfile"
+	ExportManifests = "manifests"
+)
+
+//Helmfile is the subset of a helmfile.yaml written on export
+type Helmfile struct {
+	Releases []HelmfileRelease `json:"releases"`
+}
+
+//HelmfileRelease is a Release in a helmfile.yaml, with paths relative to it
+type HelmfileRelease struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Chart     string   `json:"chart"`
+	Values    []string `json:"values,omitempty"`
+	Needs     []string `json:"needs,omitempty"`
+}
+
+//HelmfileChartDir returns the directory, relative to the helmfile.yaml, that
+//the Release's chart is unpacked into
+func HelmfileChartDir(rel *release.Release) string {
+	return path.Join("charts", rel.GetName())
+}
+
+//HelmfileValuesFile returns the file, relative to the helmfile.yaml, that the
+//Release's values are written to
+func HelmfileValuesFile(rel *release.Release) string {
+	return path.Join("values", rel.GetName()+".yaml")
+}
+
+//NewHelmfile returns a Helmfile for the Releases, each needing the one before
+//it, so that helmfile applies them in the order they were saved
+func NewHelmfile(releases []*release.Release) (helmfile Helmfile) {
+	for i, rel := range releases {
+		entry := HelmfileRelease{
+			Name:      rel.GetName(),
+			Namespace: rel.GetNamespace(),
+			Chart: "./" + path.Join(HelmfileChartDir(rel),
+				rel.GetChart().GetMetadata().GetName()),
+		}
+		if rel.GetConfig().GetRaw() != "" {
+			entry.Values = []string{HelmfileValuesFile(rel)}
+		}
+		if i > 0 {
+			previous := releases[i-1]
+			entry.Needs = []string{previous.GetNamespace() + "/" +
+				previous.GetName()}
+		}
+		helmfile.Releases = append(helmfile.Releases, entry)
+	}
+	return
+}
+
+//Marshal returns the Helmfile as YAML
+func (h Helmfile) Marshal() ([]byte, error) {
+	return yaml.Marshal(h)
+}
+
+//WriteChartDir unpacks the chart into a directory named after it within dest,
+//and returns the chart directory's path. It fails if the directory already
+//exists, unless replace is set, in which case it's removed first.
+func WriteChartDir(c *chart.Chart, dest string, replace bool) (string, error) {
+	if c.GetMetadata().GetName() == "" {
+		return "", errors.New("no chart to write")
+	}
+	dir := filepath.Join(dest, c.GetMetadata().GetName())
+	if err := ClearPath(dir, replace); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", err
+	}
+	return dir, chartutil.SaveDir(c, dest)
+}
+
+//ClearPath removes the file or directory at the path if replace is set, and
+//otherwise fails if there's one there, so nothing is overwritten by accident
+func ClearPath(path string, replace bool) error {
+	if replace {
+		return os.RemoveAll(path)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		if err == nil {
+			err = fmt.Errorf("%s already exists", path)
+		}
+		return err
+	}
+	return nil
+}
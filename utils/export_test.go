@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestNewHelmfile(t *testing.T) {
+	releases := []*release.Release{
+		{Name: "db", Namespace: "data", Config: &chart.Config{Raw: "a: 1\n"},
+			Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "postgres"}}},
+		{Name: "app", Namespace: "web",
+			Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "app"}}},
+	}
+	helmfile := NewHelmfile(releases)
+	db, app := helmfile.Releases[0], helmfile.Releases[1]
+	if db.Chart != "./charts/db/postgres" || len(db.Values) != 1 ||
+		len(db.Needs) != 0 {
+		t.Errorf("Incorrect first Release, got: %+v.", db)
+	}
+	if len(app.Values) != 0 || len(app.Needs) != 1 || app.Needs[0] != "data/db" {
+		t.Errorf("Incorrect second Release, got: %+v.", app)
+	}
+	out, err := helmfile.Marshal()
+	if err != nil || !strings.Contains(string(out), "- data/db") {
+		t.Errorf("Incorrect helmfile.yaml, got: %s, %v.", out, err)
+	}
+}
+
+func TestWriteChartDir(t *testing.T) {
+	dest, err := ioutil.TempDir("", "helm-bulk-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dest)
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "app", Version: "1.0.0"}}
+	existing := filepath.Join(dest, "app", "local.txt")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteChartDir(c, dest, false); err == nil {
+		t.Error("Existing chart directory was replaced without replace")
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("Existing chart directory was changed, got: %v", err)
+	}
+	dir, err := WriteChartDir(c, dest, true)
+	if err != nil || dir != filepath.Join(dest, "app") {
+		t.Errorf("Chart directory wasn't replaced, got: %s, %v.", dir, err)
+	}
+	if _, err := os.Stat(existing); !os.IsNotExist(err) {
+		t.Error("Replaced chart directory kept its old contents")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//Hook modes, selecting which of a Release's hooks run when it's loaded
+const (
+	HooksAll      = "all"
+	HooksNone     = "none"
+	HooksPreOnly  = "pre-only"
+	HooksPostOnly = "post-only"
+)
+
+//ValidHookMode returns whether the provided string is a known hook mode
+func ValidHookMode(mode string) bool {
+	switch mode {
+	case HooksAll, HooksNone, HooksPreOnly, HooksPostOnly:
+		return true
+	}
+	return false
+}
+
+//hookAnnotationLine matches the helm.sh/hook annotation in a template,
+//capturing its comma separated events
+var hookAnnotationLine = regexp.MustCompile(`(?m)^\s*["']?` +
+	regexp.QuoteMeta(hookAnnotation) + `["']?\s*:\s*(.*)$`)
+
+//ChartForHookMode returns the chart to load for the provided hook mode. For
+//pre-only and post-only, this is a copy of the chart without the templates of
+//hooks that have no pre-* or post-* events respectively; otherwise it's the
+//chart unchanged, as all or none of the hooks are run. Hook events are read
+//from the helm.sh/hook annotations of the chart's own templates, so a chart
+//substituted for the saved one is filtered correctly, falling back to the
+//saved hook at the same path for templates without a literal annotation, e.g.
+//one from a named template. It also returns the paths of saved hooks that
+//aren't templates of the chart.
+func ChartForHookMode(c *chart.Chart, hooks []*release.Hook,
+	mode string) (*chart.Chart, []string) {
+	if mode != HooksPreOnly && mode != HooksPostOnly {
+		return c, nil
+	}
+	saved := map[string][]string{}
+	for _, hook := range hooks {
+		for _, event := range hook.GetEvents() {
+			saved[hook.GetPath()] = append(saved[hook.GetPath()],
+				HookEventName(event))
+		}
+	}
+	filtered := proto.Clone(c).(*chart.Chart)
+	found := map[string]bool{}
+	dropTemplates(filtered, hookFilter{saved: saved,
+		phase: strings.TrimSuffix(mode, "-only"), found: found}, "")
+	var missing []string
+	for _, hook := range hooks {
+		if path := hook.GetPath(); !found[path] {
+			found[path] = true
+			missing = append(missing, path)
+		}
+	}
+	return filtered, missing
+}
+
+//hookFilter decides which templates are hooks to drop for a phase
+type hookFilter struct {
+	//saved holds the events of the saved hooks, by path
+	saved map[string][]string
+	//phase is the phase of hook events to keep, i.e. "pre" or "post"
+	phase string
+	//found records the paths of the chart's templates
+	found map[string]bool
+}
+
+//drop returns whether the template at the path is a hook without an event in
+//the filter's phase. Templates whose events can't be read are only dropped if
+//a saved hook at the same path has none in the phase.
+func (f hookFilter) drop(path string, template *chart.Template) bool {
+	f.found[path] = true
+	events, known := TemplateHookEvents(template)
+	if !known {
+		events = f.saved[path]
+	}
+	return len(events) > 0 && !hasEventWithPrefix(events, f.phase)
+}
+
+//dropTemplates removes the templates the filter drops from the chart and its
+//dependencies, where paths are as recorded in hooks, e.g.
+//mychart/charts/subchart/templates/job.yaml
+func dropTemplates(c *chart.Chart, filter hookFilter, prefix string) {
+	chartPath := prefix + c.GetMetadata().GetName() + "/"
+	var templates []*chart.Template
+	for _, template := range c.Templates {
+		if !filter.drop(chartPath+template.GetName(), template) {
+			templates = append(templates, template)
+		}
+	}
+	c.Templates = templates
+	for _, dependency := range c.Dependencies {
+		dropTemplates(dependency, filter, chartPath+"charts/")
+	}
+}
+
+//TemplateHookEvents returns the events in the template's helm.sh/hook
+//annotations. known is false if it has none, or an annotation's value is
+//itself templated, so its events can't be read without rendering it.
+func TemplateHookEvents(template *chart.Template) (events []string,
+	known bool) {
+	for _, match := range hookAnnotationLine.FindAllStringSubmatch(
+		string(template.GetData()), -1) {
+		value := match[1]
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if strings.Contains(value, "{{") {
+			return nil, false
+		}
+		for _, event := range strings.Split(value, ",") {
+			if event = strings.TrimSpace(event); event != "" {
+				events = append(events, event)
+			}
+		}
+	}
+	return events, len(events) > 0
+}
+
+//hasEventWithPrefix returns whether any of the events, as named in the
+//helm.sh/hook annotation, begin with the provided phase, i.e. "pre" or "post".
+//crd-install hooks count as pre, as they're run before the rest of the chart
+//is installed.
+func hasEventWithPrefix(events []string, phase string) bool {
+	for _, name := range events {
+		if name == HookEventName(release.Hook_CRD_INSTALL) {
+			name = "pre-" + name
+		}
+		if strings.HasPrefix(name, phase+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+//HookEventName returns the event as it appears in the helm.sh/hook annotation,
+//e.g. pre-install
+func HookEventName(event release.Hook_Event) string {
+	return strings.ToLower(strings.Replace(event.String(), "_", "-", -1))
+}
+
+//HookSummary returns a one line description of the hook, including its kind
+//and events
+func HookSummary(hook *release.Hook) string {
+	var events []string
+	for _, event := range hook.GetEvents() {
+		events = append(events, HookEventName(event))
+	}
+	return fmt.Sprintf("%s (%s): %s", hook.GetName(), hook.GetKind(),
+		strings.Join(events, ","))
+}
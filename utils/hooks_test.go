@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestChartForHookMode(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app"},
+		Templates: []*chart.Template{
+			{Name: "templates/deployment.yaml"},
+			{Name: "templates/migrate.yaml"},
+		},
+		Dependencies: []*chart.Chart{{
+			Metadata:  &chart.Metadata{Name: "db"},
+			Templates: []*chart.Template{{Name: "templates/notify.yaml"}},
+		}},
+	}
+	hooks := []*release.Hook{
+		{Path: "app/templates/migrate.yaml",
+			Events: []release.Hook_Event{release.Hook_PRE_INSTALL}},
+		{Path: "app/charts/db/templates/notify.yaml",
+			Events: []release.Hook_Event{release.Hook_POST_INSTALL}},
+	}
+	filtered, missing := ChartForHookMode(c, hooks, HooksPreOnly)
+	if len(filtered.Templates) != 2 || len(filtered.Dependencies[0].Templates) != 0 {
+		t.Errorf("Post hook templates weren't dropped, got: %v", filtered)
+	}
+	if len(c.Dependencies[0].Templates) != 1 {
+		t.Error("Original chart was modified")
+	}
+	if len(missing) != 0 {
+		t.Errorf("Saved hooks reported missing from their own chart: %v", missing)
+	}
+	filtered, _ = ChartForHookMode(c, hooks, HooksPostOnly)
+	if len(filtered.Templates) != 1 || len(filtered.Dependencies[0].Templates) != 1 {
+		t.Errorf("Pre hook templates weren't dropped, got: %v", filtered)
+	}
+}
+
+func TestChartForHookModeSubstitutedChart(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app"},
+		Templates: []*chart.Template{
+			{Name: "templates/deployment.yaml",
+				Data: []byte("kind: Deployment\n")},
+			{Name: "templates/hooks/migrate.yaml",
+				Data: []byte("kind: Job\nmetadata:\n  annotations:\n" +
+					"    \"helm.sh/hook\": pre-install,pre-upgrade\n" +
+					"    \"helm.sh/hook-weight\": \"-5\"\n")},
+			{Name: "templates/hooks/notify.yaml",
+				Data: []byte("kind: Job\nmetadata:\n  annotations:\n" +
+					"    helm.sh/hook: post-upgrade # notify chat\n")},
+		},
+	}
+	//saved from an older chart, which kept its hooks elsewhere
+	hooks := []*release.Hook{
+		{Path: "app/templates/migrate.yaml",
+			Events: []release.Hook_Event{release.Hook_PRE_INSTALL}},
+		{Path: "app/templates/notify.yaml",
+			Events: []release.Hook_Event{release.Hook_POST_UPGRADE}},
+	}
+	filtered, missing := ChartForHookMode(c, hooks, HooksPreOnly)
+	if len(filtered.Templates) != 2 ||
+		filtered.Templates[1].Name != "templates/hooks/migrate.yaml" {
+		t.Errorf("Post hook template of the loaded chart wasn't dropped, got: %v",
+			filtered.Templates)
+	}
+	if len(missing) != 2 || missing[0] != "app/templates/migrate.yaml" {
+		t.Errorf("Saved hooks missing from the chart were incorrect, got: %v",
+			missing)
+	}
+	filtered, _ = ChartForHookMode(c, hooks, HooksPostOnly)
+	if len(filtered.Templates) != 2 ||
+		filtered.Templates[1].Name != "templates/hooks/notify.yaml" {
+		t.Errorf("Pre hook template of the loaded chart wasn't dropped, got: %v",
+			filtered.Templates)
+	}
+}
+
+func TestTemplateHookEvents(t *testing.T) {
+	for _, c := range []struct {
+		data string
+		want []string
+	}{
+		{"metadata:\n  annotations:\n    helm.sh/hook: crd-install\n",
+			[]string{"crd-install"}},
+		{"metadata:\n  annotations:\n    helm.sh/hook-weight: \"1\"\n", nil},
+		{"metadata:\n  annotations:\n    helm.sh/hook: {{ .Values.hook }}\n",
+			nil},
+	} {
+		data, want := c.data, c.want
+		events, known := TemplateHookEvents(&chart.Template{Data: []byte(data)})
+		if known != (want != nil) || len(events) != len(want) ||
+			(want != nil && events[0] != want[0]) {
+			t.Errorf("Incorrect hook events for %q, got: %v, %t, want: %v.", data,
+				events, known, want)
+		}
+	}
+}
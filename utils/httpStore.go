@@ -0,0 +1,88 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+//httpStore is a Store backed by a plain HTTP(S) endpoint, which archives are
+//fetched from with GET and uploaded to with PUT. Basic auth credentials can be
+//supplied in the URL, and a bearer token in HELM_BULK_HTTP_TOKEN.
+type httpStore struct {
+	baseURL string
+}
+
+//Get downloads the named archive
+func (s httpStore) Get(name string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+//Put uploads the contents of the Reader as the named archive
+func (s httpStore) Put(name string, r io.Reader) error {
+	resp, err := s.do(http.MethodPut, name, r)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+//List isn't supported, as plain HTTP has no standard way to list a directory
+func (s httpStore) List(prefix string) ([]string, error) {
+	return nil, errors.New("listing archives is not supported for http(s) URLs")
+}
+
+//Delete removes the named archive with a DELETE request
+func (s httpStore) Delete(name string) error {
+	resp, err := s.do(http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+//do sends a request for the named archive, returning an error for any non-2xx
+//response, which is a NotFoundError for a 404
+func (s httpStore) do(method, name string, body io.Reader) (*http.Response, error) {
+	url := strings.TrimSuffix(s.baseURL, "/") + "/" + name
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if token := envOrDefault("HELM_BULK_HTTP_TOKEN", ""); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, NotFoundError{Name: url}
+	}
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return resp, nil
+}
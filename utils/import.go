@@ -0,0 +1,112 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/timeconv"
+)
+
+//ImportDescription is the description given to imported Releases
+const ImportDescription = "Imported by helm-bulk"
+
+//NewRelease returns a Release for the chart and values, as the first DEPLOYED
+//revision, like those saved from a Cluster
+func NewRelease(name, namespace string, c *chart.Chart,
+	values string) *release.Release {
+	now := timeconv.Now()
+	return &release.Release{
+		Name:      name,
+		Namespace: namespace,
+		Chart:     c,
+		Config:    &chart.Config{Raw: values},
+		Version:   1,
+		Info: &release.Info{
+			Status:        &release.Status{Code: release.Status_DEPLOYED},
+			FirstDeployed: now,
+			LastDeployed:  now,
+			Description:   ImportDescription,
+		},
+	}
+}
+
+//ReadValues reads the values files, merging them such that later files take
+//precedence, and returns the result as YAML. A single file is returned as is.
+func ReadValues(files []string) (string, error) {
+	if len(files) == 1 {
+		data, err := ioutil.ReadFile(files[0])
+		return string(data), err
+	}
+	merged := map[string]interface{}{}
+	for _, file := range files {
+		values, err := chartutil.ReadValuesFile(file)
+		if err != nil {
+			return "", err
+		}
+		mergeValues(merged, values)
+	}
+	if len(merged) == 0 {
+		return "", nil
+	}
+	return chartutil.Values(merged).YAML()
+}
+
+//mergeValues merges src into dest, recursing into maps present in both
+func mergeValues(dest, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcOK := value.(map[string]interface{})
+		destMap, destOK := dest[key].(map[string]interface{})
+		if srcOK && destOK {
+			mergeValues(destMap, srcMap)
+		} else {
+			dest[key] = value
+		}
+	}
+}
+
+//ReadHelmfile reads a helmfile.yaml, e.g. one written by export, making the
+//relative chart and values paths of its Releases relative to the working
+//directory rather than to the file
+func ReadHelmfile(file string) (helmfile Helmfile, err error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	if err = yaml.Unmarshal(data, &helmfile); err != nil {
+		return
+	}
+	dir := filepath.Dir(file)
+	for i, rel := range helmfile.Releases {
+		helmfile.Releases[i].Chart = relativeTo(dir, rel.Chart)
+		for j, values := range rel.Values {
+			helmfile.Releases[i].Values[j] = relativeTo(dir, values)
+		}
+	}
+	return
+}
+
+//relativeTo returns the path within dir, unless it's absolute
+func relativeTo(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-bulk-values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.yaml")
+	ioutil.WriteFile(base, []byte("# base\nimage:\n  tag: v1\n  repo: app\n"),
+		0644)
+	ioutil.WriteFile(prod, []byte("image:\n  tag: v2\n"), 0644)
+	values, err := ReadValues([]string{base})
+	if err != nil || values != "# base\nimage:\n  tag: v1\n  repo: app\n" {
+		t.Errorf("Single values file not returned as is, got: %s, %v.", values,
+			err)
+	}
+	values, err = ReadValues([]string{base, prod})
+	expected := "image:\n  repo: app\n  tag: v2\n"
+	if err != nil || values != expected {
+		t.Errorf("Values were incorrect, got: %s, want: %s.", values, expected)
+	}
+}
+
+func TestReadHelmfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-bulk-helmfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "helmfile.yaml")
+	absolute := filepath.Join(os.TempDir(), "values", "shared.yaml")
+	ioutil.WriteFile(file, []byte("releases:\n- name: app\n  namespace: web\n"+
+		"  chart: ./charts/app/nginx\n  values:\n  - values/app.yaml\n"+
+		"  - "+absolute+"\n"), 0644)
+	helmfile, err := ReadHelmfile(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rel := helmfile.Releases[0]
+	if rel.Chart != filepath.Join(dir, "charts/app/nginx") ||
+		rel.Values[0] != filepath.Join(dir, "values/app.yaml") {
+		t.Errorf("Paths were not made relative to the file, got: %+v.", rel)
+	}
+	if rel.Values[1] != absolute {
+		t.Errorf("Absolute path was changed, got: %s, want: %s.", rel.Values[1],
+			absolute)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+//Journal outcomes
+const (
+	OutcomeOK     = "ok"
+	OutcomeFailed = "failed"
+)
+
+//JournalEntry records the outcome of a single action taken on a Release during
+//a load
+type JournalEntry struct {
+	Release string    `json:"release"`
+	Action  string    `json:"action"`
+	Outcome string    `json:"outcome"`
+	Time    time.Time `json:"time"`
+}
+
+//Journal is an append-only log of JournalEntries, one JSON object per line,
+//that's synced to disk after every entry so it survives the process being
+//killed
+type Journal struct {
+	file *os.File
+}
+
+//OpenJournal opens the journal at the provided path, appending to it when
+//resuming, or starting afresh otherwise
+func OpenJournal(path string, resume bool) (*Journal, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: file}, nil
+}
+
+//Record appends an entry to the journal
+func (j *Journal) Record(release, action, outcome string) error {
+	entry, err := json.Marshal(JournalEntry{Release: release, Action: action,
+		Outcome: outcome, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	if _, err = j.file.Write(append(entry, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+//Close closes the journal file
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+//ReadJournal returns the entries in the journal at the provided path. A
+//journal that doesn't exist has no entries.
+func ReadJournal(path string) (entries []JournalEntry, err error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		//a line cut short by the process being killed is ignored
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+//CompletedActions returns the actions that completed successfully for each
+//Release in the journal entries, keyed by Release name then action
+func CompletedActions(entries []JournalEntry) map[string]map[string]bool {
+	completed := map[string]map[string]bool{}
+	for _, entry := range entries {
+		if entry.Outcome != OutcomeOK {
+			continue
+		}
+		if completed[entry.Release] == nil {
+			completed[entry.Release] = map[string]bool{}
+		}
+		completed[entry.Release][entry.Action] = true
+	}
+	return completed
+}
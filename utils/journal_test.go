@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalResume(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "helm-bulk")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "helm-releases.journal")
+	journal, _ := OpenJournal(path, false)
+	journal.Record("crds", "purge", OutcomeOK)
+	journal.Record("crds", "install", OutcomeOK)
+	journal.Close()
+	journal, _ = OpenJournal(path, true)
+	journal.Record("app", "install", OutcomeFailed)
+	journal.Close()
+	//simulate the process being killed mid-write
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString(`{"release":"db","act`)
+	file.Close()
+
+	entries, err := ReadJournal(path)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Incorrect journal entries, got: %v, err: %v", entries, err)
+	}
+	completed := CompletedActions(entries)
+	if !completed["crds"]["install"] || len(completed["app"]) != 0 {
+		t.Errorf("Incorrect completed actions, got: %v", completed)
+	}
+
+	journal, _ = OpenJournal(path, false)
+	journal.Close()
+	if entries, _ = ReadJournal(path); len(entries) != 0 {
+		t.Errorf("Journal wasn't truncated for a fresh load, got: %v", entries)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//APIDeprecation records the Kubernetes version in which an apiVersion of a
+//kind was deprecated, and the version in which it was removed
+type APIDeprecation struct {
+	APIVersion   string
+	Kind         string
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+//apiDeprecations is the built-in table of deprecated and removed apiVersions
+var apiDeprecations = []APIDeprecation{
+	{"extensions/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.11", "1.16", "policy/v1beta1"},
+	{"extensions/v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"},
+	{"apps/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.16", "1.22",
+		"apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.19", "1.22",
+		"apiregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration",
+		"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration",
+		"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1alpha1", "", "1.17", "1.22",
+		"rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "", "1.17", "1.22",
+		"rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.14", "1.22",
+		"scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.19", "1.22",
+		"storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.17", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.24", "1.27",
+		"storage.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.19", "1.22",
+		"certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.14", "1.22",
+		"coordination.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "1.21", "1.25", "batch/v1"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.21", "1.25", ""},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.21", "1.25",
+		"discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.19", "1.25", "events.k8s.io/v1"},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.20", "1.25", "node.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25",
+		"autoscaling/v2"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26",
+		"autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "", "1.23", "1.26",
+		"flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "", "1.26", "1.29",
+		"flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "", "1.29", "1.32",
+		"flowcontrol.apiserver.k8s.io/v1"},
+}
+
+//LintFinding is a resource in a Release using an apiVersion that's deprecated
+//or removed in the target Kubernetes version
+type LintFinding struct {
+	Release     string
+	Resource    string
+	Removed     bool
+	Deprecation APIDeprecation
+}
+
+//Status returns REMOVED or DEPRECATED, with the Kubernetes version since when
+func (f LintFinding) Status() string {
+	if f.Removed {
+		return "REMOVED in " + f.Deprecation.RemovedIn
+	}
+	return "DEPRECATED in " + f.Deprecation.DeprecatedIn
+}
+
+//LintRelease returns a finding for each resource in the Release's manifest and
+//hooks using an apiVersion that's deprecated or removed in the Kubernetes
+//version, e.g. 1.22
+func LintRelease(rel *release.Release, kubeVersion string) ([]LintFinding,
+	error) {
+	target, err := parseKubeVersion(kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := ReleaseResources(rel)
+	if err != nil {
+		return nil, err
+	}
+	var findings []LintFinding
+	for _, resource := range resources {
+		deprecation, ok := findDeprecation(resource)
+		if !ok {
+			continue
+		}
+		removed := atLeast(target, deprecation.RemovedIn)
+		if removed || atLeast(target, deprecation.DeprecatedIn) {
+			findings = append(findings, LintFinding{
+				Release:     rel.GetName(),
+				Resource:    resource.APIVersion + " " + resourceKey(resource),
+				Removed:     removed,
+				Deprecation: deprecation,
+			})
+		}
+	}
+	return findings, nil
+}
+
+//findDeprecation returns the entry in the table for the resource's apiVersion
+//and kind, if there is one
+func findDeprecation(resource Resource) (APIDeprecation, bool) {
+	for _, deprecation := range apiDeprecations {
+		if deprecation.APIVersion == resource.APIVersion &&
+			(deprecation.Kind == "" || deprecation.Kind == resource.Kind) {
+			return deprecation, true
+		}
+	}
+	return APIDeprecation{}, false
+}
+
+//kubeVersion is a Kubernetes major and minor version
+type kubeVersion struct {
+	major, minor int
+}
+
+//parseKubeVersion parses a Kubernetes version such as 1.22, v1.22 or 1.22.3
+func parseKubeVersion(version string) (v kubeVersion, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return v, fmt.Errorf("invalid Kubernetes version: %s", version)
+	}
+	if v.major, err = strconv.Atoi(parts[0]); err != nil {
+		return v, fmt.Errorf("invalid Kubernetes version: %s", version)
+	}
+	if v.minor, err = strconv.Atoi(strings.TrimSuffix(parts[1], "+")); err != nil {
+		return v, fmt.Errorf("invalid Kubernetes version: %s", version)
+	}
+	return v, nil
+}
+
+//atLeast returns whether the target is the same as or newer than the version
+//from the table
+func atLeast(target kubeVersion, version string) bool {
+	v, err := parseKubeVersion(version)
+	if err != nil {
+		return false
+	}
+	return target.major > v.major ||
+		(target.major == v.major && target.minor >= v.minor)
+}
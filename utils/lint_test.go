@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestLintRelease(t *testing.T) {
+	rel := &release.Release{Name: "app", Manifest: testManifest,
+		Hooks: []*release.Hook{{Manifest: "apiVersion: batch/v1beta1\n" +
+			"kind: CronJob\nmetadata:\n  name: backup\n"}}}
+	findings, err := LintRelease(rel, "1.15")
+	if err != nil || len(findings) != 1 || findings[0].Removed {
+		t.Errorf("Incorrect findings for 1.15, got: %+v, %v.", findings, err)
+	}
+	findings, err = LintRelease(rel, "v1.22.3")
+	if err != nil || len(findings) != 2 || !findings[0].Removed ||
+		findings[0].Resource != "extensions/v1beta1 Deployment/app" ||
+		findings[1].Removed {
+		t.Errorf("Incorrect findings for 1.22, got: %+v, %v.", findings, err)
+	}
+	if _, err := LintRelease(rel, "latest"); err == nil {
+		t.Error("No error returned for an invalid Kubernetes version")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+//Resource is a single Kubernetes resource from a Release's manifest
+type Resource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	//Manifest is the resource's YAML document
+	Manifest string `json:"-"`
+}
+
+//ManifestResources splits a manifest into its resources, in order, skipping
+//any documents without a kind, e.g. those only holding comments
+func ManifestResources(manifest string) (resources []Resource, err error) {
+	for _, doc := range splitManifest(manifest) {
+		var resource Resource
+		if err = yaml.Unmarshal([]byte(doc), &resource); err != nil {
+			return nil, err
+		}
+		if resource.Kind != "" {
+			resource.Manifest = doc
+			resources = append(resources, resource)
+		}
+	}
+	return
+}
+
+//splitManifest splits a manifest into its YAML documents, in order
+func splitManifest(manifest string) (docs []string) {
+	split := releaseutil.SplitManifests(manifest)
+	for i := 0; i < len(split); i++ {
+		docs = append(docs, split[fmt.Sprintf("manifest-%d", i)])
+	}
+	return
+}
+
+//ManifestFiles returns the files to export a Release's manifest to, keyed by
+//path relative to the Release's directory: a kind-name.yaml file per resource,
+//and the same per hook in a hooks directory
+func ManifestFiles(rel *release.Release) (map[string]string, error) {
+	files := map[string]string{}
+	resources, err := ManifestResources(rel.GetManifest())
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		addManifestFile(files, "", resource.Kind, resource.Metadata.Name,
+			resource.Manifest)
+	}
+	for _, hook := range rel.GetHooks() {
+		addManifestFile(files, "hooks", hook.GetKind(), hook.GetName(),
+			strings.TrimSpace(hook.GetManifest()))
+	}
+	return files, nil
+}
+
+//addManifestFile adds a kind-name.yaml file to the files, numbering it if the
+//name's already taken
+func addManifestFile(files map[string]string, dir, kind, name, doc string) {
+	base := strings.ToLower(kind) + "-" + name
+	file := path.Join(dir, base+".yaml")
+	for i := 2; files[file] != ""; i++ {
+		file = path.Join(dir, fmt.Sprintf("%s-%d.yaml", base, i))
+	}
+	files[file] = doc + "\n"
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+const testManifest = `---
+# Source: app/templates/empty.yaml
+---
+# Source: app/templates/svc.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: web
+---
+# Source: app/templates/deploy.yaml
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: app
+`
+
+func TestManifestResources(t *testing.T) {
+	resources, err := ManifestResources(testManifest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resources) != 2 || resources[0].Kind != "Service" ||
+		resources[0].Metadata.Namespace != "web" ||
+		resources[1].APIVersion != "extensions/v1beta1" {
+		t.Errorf("Incorrect resources returned, got: %+v.", resources)
+	}
+}
+
+func TestManifestFiles(t *testing.T) {
+	rel := &release.Release{Manifest: testManifest, Hooks: []*release.Hook{
+		{Kind: "Job", Name: "migrate", Manifest: "kind: Job\n"},
+		{Kind: "Job", Name: "migrate", Manifest: "kind: Job\n"},
+	}}
+	files, err := ManifestFiles(rel)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, name := range []string{"service-app.yaml", "deployment-app.yaml",
+		"hooks/job-migrate.yaml", "hooks/job-migrate-2.yaml"} {
+		if files[name] == "" {
+			t.Errorf("File %s missing, got: %v.", name, files)
+		}
+	}
+	if len(files) != 4 {
+		t.Errorf("Incorrect number of files, got: %d, want: %d.", len(files), 4)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/version"
+)
+
+//ReleaseResources returns the resources in the Release's manifest, followed by
+//those in its hooks
+func ReleaseResources(rel *release.Release) ([]Resource, error) {
+	manifest := rel.GetManifest()
+	for _, hook := range rel.GetHooks() {
+		manifest += "\n---\n" + hook.GetManifest()
+	}
+	return ManifestResources(manifest)
+}
+
+//VerifyReleases returns a problem for each Release that's incomplete, or whose
+//name is used by an earlier Release
+func VerifyReleases(releases []*release.Release) (problems []string) {
+	seen := map[string]string{}
+	for i, rel := range releases {
+		switch {
+		case rel.GetName() == "":
+			problems = append(problems, fmt.Sprintf("Release %d has no name", i+1))
+		case rel.GetChart().GetMetadata().GetName() == "":
+			problems = append(problems, "Release "+rel.GetName()+" has no chart")
+		}
+		if namespace, ok := seen[rel.GetName()]; ok {
+			problems = append(problems, fmt.Sprintf("Release %s is in both"+
+				" namespace %s and %s", rel.GetName(), namespace,
+				rel.GetNamespace()))
+		}
+		seen[rel.GetName()] = rel.GetNamespace()
+	}
+	return
+}
+
+//IncompatibleCharts returns the names and tillerVersion constraints of the
+//Release's chart and its dependencies that the Tiller version doesn't satisfy
+func IncompatibleCharts(rel *release.Release, tillerVersion string) []string {
+	return incompatibleCharts(rel.GetChart(), tillerVersion)
+}
+
+//incompatibleCharts returns the names and tillerVersion constraints of the
+//chart and its dependencies that the Tiller version doesn't satisfy
+func incompatibleCharts(c *chart.Chart, tillerVersion string) (incompatible []string) {
+	constraint := c.GetMetadata().GetTillerVersion()
+	if constraint != "" && !version.IsCompatibleRange(constraint, tillerVersion) {
+		incompatible = append(incompatible, c.GetMetadata().GetName()+" "+
+			constraint)
+	}
+	for _, dependency := range c.GetDependencies() {
+		incompatible = append(incompatible,
+			incompatibleCharts(dependency, tillerVersion)...)
+	}
+	return
+}
+
+//crd is the subset of a CustomResourceDefinition giving the kinds it defines
+type crd struct {
+	Spec struct {
+		Group    string `json:"group"`
+		Version  string `json:"version"`
+		Versions []struct {
+			Name string `json:"name"`
+		} `json:"versions"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+	} `json:"spec"`
+}
+
+//DefinedKinds returns the kinds defined by the CustomResourceDefinitions among
+//the resources, keyed by apiVersion and kind, e.g. "example.com/v1 Widget"
+func DefinedKinds(resources []Resource) (map[string]bool, error) {
+	kinds := map[string]bool{}
+	for _, resource := range resources {
+		if resource.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		var definition crd
+		if err := yaml.Unmarshal([]byte(resource.Manifest), &definition); err != nil {
+			return nil, err
+		}
+		spec := definition.Spec
+		if spec.Version != "" {
+			kinds[spec.Group+"/"+spec.Version+" "+spec.Names.Kind] = true
+		}
+		for _, v := range spec.Versions {
+			kinds[spec.Group+"/"+v.Name+" "+spec.Names.Kind] = true
+		}
+	}
+	return kinds, nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestVerifyReleases(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "app"}}
+	releases := []*release.Release{
+		{Name: "app", Namespace: "web", Chart: c},
+		{Name: "db", Namespace: "data"},
+		{Name: "app", Namespace: "staging", Chart: c},
+	}
+	if problems := VerifyReleases(releases); len(problems) != 2 {
+		t.Errorf("Incorrect problems, got: %v.", problems)
+	}
+}
+
+func TestIncompatibleCharts(t *testing.T) {
+	rel := &release.Release{Chart: &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app", TillerVersion: ">=2.10.0"},
+		Dependencies: []*chart.Chart{{Metadata: &chart.Metadata{Name: "db",
+			TillerVersion: ">=2.14.0"}}},
+	}}
+	incompatible := IncompatibleCharts(rel, "v2.13.1")
+	if len(incompatible) != 1 || incompatible[0] != "db >=2.14.0" {
+		t.Errorf("Incorrect incompatible charts, got: %v.", incompatible)
+	}
+}
+
+func TestDefinedKinds(t *testing.T) {
+	resources, _ := ManifestResources(`apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  versions:
+  - name: v1
+  names:
+    kind: Widget
+`)
+	kinds, err := DefinedKinds(resources)
+	if err != nil || !kinds["example.com/v1 Widget"] {
+		t.Errorf("Incorrect defined kinds, got: %v, %v.", kinds, err)
+	}
+}
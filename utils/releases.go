@@ -16,6 +16,13 @@ package utils
 
 import "k8s.io/helm/pkg/proto/hapi/release"
 
+//Release identities, selecting whether Releases are matched by name alone, or
+//by namespace and name
+const (
+	IdentityName          = "name"
+	IdentityNamespaceName = "namespace-name"
+)
+
 //ContainsRelease returns a bool indicating whether the provided Release is
 //in the provided slice
 func ContainsRelease(queryRelease *release.Release,
@@ -28,3 +35,54 @@ func ContainsRelease(queryRelease *release.Release,
 	}
 	return
 }
+
+//ReleaseIndex returns the index of the named Release in the provided slice, or
+//-1 if it isn't there
+func ReleaseIndex(name string, releases []*release.Release) int {
+	for i, release := range releases {
+		if release.GetName() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+//ValidIdentity returns whether the provided string is a known Release identity
+func ValidIdentity(identity string) bool {
+	return identity == IdentityName || identity == IdentityNamespaceName
+}
+
+//SameRelease returns whether the provided Releases are the same Release under
+//the provided identity
+func SameRelease(a, b *release.Release, identity string) bool {
+	if a.GetName() != b.GetName() {
+		return false
+	}
+	return identity == IdentityName || a.GetNamespace() == b.GetNamespace()
+}
+
+//MatchRelease returns the Release in the provided slice that's the same as the
+//query Release under the provided identity, or nil if there isn't one
+func MatchRelease(queryRelease *release.Release, targetReleases []*release.Release,
+	identity string) *release.Release {
+	for _, release := range targetReleases {
+		if SameRelease(queryRelease, release, identity) {
+			return release
+		}
+	}
+	return nil
+}
+
+//ConflictingRelease returns the Release in the provided slice with the same
+//name as the query Release but in a different namespace, or nil if there isn't
+//one. Tiller Release names are cluster-wide, so the two can't coexist.
+func ConflictingRelease(queryRelease *release.Release,
+	targetReleases []*release.Release) *release.Release {
+	for _, release := range targetReleases {
+		if queryRelease.GetName() == release.GetName() &&
+			queryRelease.GetNamespace() != release.GetNamespace() {
+			return release
+		}
+	}
+	return nil
+}
@@ -16,3 +16,19 @@ func TestContainsRelease(t *testing.T) {
 		t.Errorf("Incorrect bool returned, got: %t, want: %t.", containsRelease, expected)
 	}
 }
+
+func TestMatchRelease(t *testing.T) {
+	queryRelease := release.Release{Name: "app", Namespace: "prod"}
+	targetReleases := []*release.Release{{Name: "app", Namespace: "staging"}}
+	if MatchRelease(&queryRelease, targetReleases, IdentityNamespaceName) != nil {
+		t.Error("Release in another namespace matched by namespace-name")
+	}
+	if MatchRelease(&queryRelease, targetReleases, IdentityName) == nil {
+		t.Error("Release in another namespace not matched by name")
+	}
+	conflict := ConflictingRelease(&queryRelease, targetReleases)
+	if conflict.GetNamespace() != "staging" {
+		t.Errorf("Incorrect conflicting Release, got: %v, want namespace: %s.",
+			conflict, "staging")
+	}
+}
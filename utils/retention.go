@@ -0,0 +1,134 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	archiveExtension = ".tar.gz"
+	//ArchiveTimestampFormat is the UTC timestamp appended to archive names by
+	//save --timestamp, e.g. helm-releases-20190509T153000Z.tar.gz
+	ArchiveTimestampFormat = "20060102T150405Z"
+)
+
+//TimestampedArchive is an archive name along with the time it was saved
+type TimestampedArchive struct {
+	Name string
+	Time time.Time
+}
+
+//RetentionPolicy is a grandfather-father-son policy describing which
+//timestamped archives to keep; the most recent KeepLast archives, plus the
+//newest archive from each of the most recent KeepDaily days and KeepWeekly
+//ISO weeks
+type RetentionPolicy struct {
+	KeepLast   int
+	KeepDaily  int
+	KeepWeekly int
+}
+
+//TimestampedArchiveName inserts the UTC timestamp into the provided archive
+//name, before the extension
+func TimestampedArchiveName(name string, t time.Time) string {
+	return archiveStem(name) + "-" + t.UTC().Format(ArchiveTimestampFormat) +
+		archiveExtension
+}
+
+//TimestampedArchives lists the archives in the Store that are timestamped
+//versions of the provided archive name, newest first
+func TimestampedArchives(store Store, name string) (archives []TimestampedArchive,
+	err error) {
+	prefix := archiveStem(name) + "-"
+	names, err := store.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range names {
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(candidate, prefix),
+			archiveExtension)
+		t, parseErr := time.Parse(ArchiveTimestampFormat, timestamp)
+		if parseErr == nil && strings.HasSuffix(candidate, archiveExtension) {
+			archives = append(archives, TimestampedArchive{Name: candidate, Time: t})
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].Time.After(archives[j].Time)
+	})
+	return
+}
+
+//LatestArchive returns the name of the newest timestamped version of the
+//provided archive name in the Store
+func LatestArchive(store Store, name string) (string, error) {
+	archives, err := TimestampedArchives(store, name)
+	if err != nil {
+		return "", err
+	}
+	if len(archives) == 0 {
+		return "", errors.New("no timestamped archives found for " + name)
+	}
+	return archives[0].Name, nil
+}
+
+//Prune returns the archives that the policy doesn't retain. The provided
+//archives must be sorted newest first, as returned by TimestampedArchives.
+func (p RetentionPolicy) Prune(archives []TimestampedArchive) (remove []TimestampedArchive) {
+	keep := map[string]bool{}
+	for i := 0; i < p.KeepLast && i < len(archives); i++ {
+		keep[archives[i].Name] = true
+	}
+	keepNewestPerPeriod(archives, p.KeepDaily, keep, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepNewestPerPeriod(archives, p.KeepWeekly, keep, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	for _, archive := range archives {
+		if !keep[archive.Name] {
+			remove = append(remove, archive)
+		}
+	}
+	return
+}
+
+//keepNewestPerPeriod marks the newest archive in each of the most recent
+//periods (as identified by the period func) as kept, up to limit periods
+func keepNewestPerPeriod(archives []TimestampedArchive, limit int,
+	keep map[string]bool, period func(time.Time) string) {
+	seen := map[string]bool{}
+	for _, archive := range archives {
+		key := period(archive.Time)
+		if seen[key] {
+			continue
+		}
+		if len(seen) >= limit {
+			return
+		}
+		seen[key] = true
+		keep[archive.Name] = true
+	}
+}
+
+//archiveStem returns the archive name without its extension
+func archiveStem(name string) string {
+	return strings.TrimSuffix(name, archiveExtension)
+}
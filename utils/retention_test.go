@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampedArchiveName(t *testing.T) {
+	actualString := TimestampedArchiveName("backups/helm-releases.tar.gz",
+		time.Date(2019, 5, 9, 15, 30, 0, 0, time.UTC))
+	expectedString := "backups/helm-releases-20190509T153000Z.tar.gz"
+	if actualString != expectedString {
+		t.Errorf("Archive name was incorrect, got: %s, want: %s.",
+			actualString, expectedString)
+	}
+}
+
+func TestLatestArchive(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "helm-bulk")
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"helm-releases.tar.gz",
+		"helm-releases-20190509T153000Z.tar.gz",
+		"helm-releases-20190510T090000Z.tar.gz",
+		"helm-releases-notatimestamp.tar.gz",
+		"other-20190511T090000Z.tar.gz"} {
+		ioutil.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+	actualString, err := LatestArchive(localStore{dir: dir}, "helm-releases.tar.gz")
+	expectedString := "helm-releases-20190510T090000Z.tar.gz"
+	if err != nil || actualString != expectedString {
+		t.Errorf("Latest archive was incorrect, got: %s, want: %s.",
+			actualString, expectedString)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	//one archive every 12 hours over 3 weeks, newest first
+	var archives []TimestampedArchive
+	newest := time.Date(2019, 5, 26, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 42; i++ {
+		archiveTime := newest.Add(time.Duration(-12*i) * time.Hour)
+		archives = append(archives, TimestampedArchive{
+			Name: TimestampedArchiveName("helm-releases.tar.gz", archiveTime),
+			Time: archiveTime,
+		})
+	}
+	policy := RetentionPolicy{KeepLast: 3, KeepDaily: 4, KeepWeekly: 3}
+	remove := policy.Prune(archives)
+	//last 3 (26th 12:00, 26th 00:00, 25th 12:00), dailies 24th and 23rd, plus
+	//the newest of the 2 earlier ISO weeks (19th and 12th)
+	expectedKept := []string{"20190526T120000Z", "20190526T000000Z",
+		"20190525T120000Z", "20190524T120000Z", "20190523T120000Z",
+		"20190519T120000Z", "20190512T120000Z"}
+	if len(archives)-len(remove) != len(expectedKept) {
+		t.Errorf("Incorrect number of archives kept, got: %d, want: %d.",
+			len(archives)-len(remove), len(expectedKept))
+	}
+	for _, archive := range remove {
+		for _, kept := range expectedKept {
+			if strings.Contains(archive.Name, kept) {
+				t.Errorf("Archive %s should have been kept", archive.Name)
+			}
+		}
+	}
+}
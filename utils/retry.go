@@ -0,0 +1,84 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//maxBackoff caps the exponential backoff between retries
+const maxBackoff = 30 * time.Second
+
+//waitTimeoutMessage is the message of the error Tiller returns when --wait
+//times out before a Release is ready, from Kubernetes' wait.ErrWaitTimeout
+const waitTimeoutMessage = "timed out waiting for the condition"
+
+//RetryPolicy describes how many times, and how often, to retry a Tiller call
+//that fails with a transient error
+type RetryPolicy struct {
+	Retries int
+	Backoff time.Duration
+	//sleep is swapped out in tests
+	sleep func(time.Duration)
+}
+
+//Do calls op, retrying it while it fails with a retryable error, up to the
+//policy's number of retries. Backoff doubles after each attempt, up to
+//maxBackoff, with jitter so that concurrent runs don't retry in lockstep.
+func (p RetryPolicy) Do(name string, op func() error) (err error) {
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	backoff := p.Backoff
+	for attempt := 0; ; attempt++ {
+		err = op()
+		if err == nil || attempt >= p.Retries || !Retryable(err) {
+			return
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.Println(name, "failed:", err.Error(), "- retrying in", wait)
+		sleep(wait)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+//Retryable returns whether a failed idempotent Tiller call can be retried,
+//based on its gRPC status code being a transient one, e.g. Unavailable when the
+//port-forward tunnel dropped
+func Retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Aborted:
+		return true
+	}
+	return false
+}
+
+//WaitTimedOut returns whether a failed install or upgrade failed because the
+//Release didn't become ready before the --wait timeout, rather than e.g. for a
+//template error or a name clash
+func WaitTimedOut(err error) bool {
+	return err != nil &&
+		strings.Contains(status.Convert(err).Message(), waitTimeoutMessage)
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+//flakyClient is a fake Tiller backend that fails the first failures calls with
+//the provided gRPC status code, then delegates to helm.FakeClient
+type flakyClient struct {
+	*helm.FakeClient
+	code     codes.Code
+	failures int
+	calls    int
+}
+
+func (c *flakyClient) fail() error {
+	c.calls++
+	if c.calls <= c.failures {
+		return status.Error(c.code, "injected failure")
+	}
+	return nil
+}
+
+func (c *flakyClient) ListReleases(opts ...helm.ReleaseListOption) (
+	*rls.ListReleasesResponse, error) {
+	if err := c.fail(); err != nil {
+		return nil, err
+	}
+	return c.FakeClient.ListReleases(opts...)
+}
+
+func (c *flakyClient) InstallReleaseFromChart(ch *chart.Chart, ns string,
+	opts ...helm.InstallOption) (*rls.InstallReleaseResponse, error) {
+	if err := c.fail(); err != nil {
+		return nil, err
+	}
+	return c.FakeClient.InstallReleaseFromChart(ch, ns, opts...)
+}
+
+func newFlakyClient(code codes.Code, failures int) (*flakyClient, helm.Interface) {
+	flaky := &flakyClient{
+		FakeClient: &helm.FakeClient{Rels: []*release.Release{{Name: "dummy"}}},
+		code:       code,
+		failures:   failures,
+	}
+	policy := RetryPolicy{Retries: 3, Backoff: time.Second,
+		sleep: func(time.Duration) {}}
+	return flaky, WithRetries(flaky, policy)
+}
+
+func TestRetryTransientFailures(t *testing.T) {
+	flaky, client := newFlakyClient(codes.Unavailable, 2)
+	resp, err := client.ListReleases()
+	if err != nil || len(resp.GetReleases()) != 1 {
+		t.Errorf("ListReleases wasn't retried to success, got err: %v", err)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("Incorrect number of calls, got: %d, want: %d.", flaky.calls, 3)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	flaky, client := newFlakyClient(codes.Unavailable, 10)
+	if _, err := client.ListReleases(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected the last Unavailable error, got: %v", err)
+	}
+	if flaky.calls != 4 {
+		t.Errorf("Incorrect number of calls, got: %d, want: %d.", flaky.calls, 4)
+	}
+}
+
+func TestRetryClassification(t *testing.T) {
+	for _, tc := range []struct {
+		code          codes.Code
+		install       bool
+		expectedCalls int
+	}{
+		{codes.InvalidArgument, false, 1},
+		{codes.DeadlineExceeded, false, 2},
+		{codes.DeadlineExceeded, true, 1},
+		{codes.Unavailable, true, 1},
+	} {
+		flaky, client := newFlakyClient(tc.code, 1)
+		if tc.install {
+			client.InstallReleaseFromChart(&chart.Chart{}, "default",
+				helm.ReleaseName("dummy2"))
+		} else {
+			client.ListReleases()
+		}
+		if flaky.calls != tc.expectedCalls {
+			t.Errorf("Incorrect number of calls for %s (install: %t), got: %d,"+
+				" want: %d.", tc.code, tc.install, flaky.calls, tc.expectedCalls)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var slept []time.Duration
+	policy := RetryPolicy{Retries: 3, Backoff: time.Second,
+		sleep: func(d time.Duration) { slept = append(slept, d) }}
+	policy.Do("op", func() error {
+		return status.Error(codes.Unavailable, "injected failure")
+	})
+	if len(slept) != 3 {
+		t.Errorf("Incorrect number of retries, got: %d, want: %d.", len(slept), 3)
+	}
+	for i, d := range slept {
+		max := time.Second << uint(i)
+		if d < max/2 || d > max {
+			t.Errorf("Backoff %d out of range, got: %s, want: %s-%s.", i, d,
+				max/2, max)
+		}
+	}
+}
+
+func TestWaitTimedOut(t *testing.T) {
+	for _, c := range []struct {
+		err      error
+		timedOut bool
+	}{
+		{status.Error(codes.Unknown,
+			"release app failed: timed out waiting for the condition"), true},
+		{status.Error(codes.Unknown,
+			"release app failed: services \"app\" already exists"), false},
+		{status.Error(codes.Unavailable, "transport is closing"), false},
+		{nil, false},
+	} {
+		if timedOut := WaitTimedOut(c.err); timedOut != c.timedOut {
+			t.Errorf("Incorrect wait timeout for %v, got: %t, want: %t.", c.err,
+				timedOut, c.timedOut)
+		}
+	}
+}
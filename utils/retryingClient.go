@@ -0,0 +1,75 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"k8s.io/helm/pkg/helm"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+//retryingClient is a helm.Interface that retries calls to the wrapped client
+//according to a RetryPolicy. Only idempotent calls are overridden to retry, as
+//a call that changes a Release, e.g. an install, may have been applied before
+//it failed, e.g. when the tunnel drops mid-call, and would be applied twice.
+type retryingClient struct {
+	helm.Interface
+	policy RetryPolicy
+}
+
+//WithRetries wraps the provided client so that calls failing with transient
+//errors are retried according to the policy
+func WithRetries(client helm.Interface, policy RetryPolicy) helm.Interface {
+	return &retryingClient{Interface: client, policy: policy}
+}
+
+//ListReleases retries helm.Interface.ListReleases, which is idempotent
+func (c *retryingClient) ListReleases(opts ...helm.ReleaseListOption) (
+	resp *rls.ListReleasesResponse, err error) {
+	err = c.policy.Do("list Releases", func() (err error) {
+		resp, err = c.Interface.ListReleases(opts...)
+		return
+	})
+	return
+}
+
+//GetVersion retries helm.Interface.GetVersion, which is idempotent
+func (c *retryingClient) GetVersion(opts ...helm.VersionOption) (
+	resp *rls.GetVersionResponse, err error) {
+	err = c.policy.Do("get Tiller version", func() (err error) {
+		resp, err = c.Interface.GetVersion(opts...)
+		return
+	})
+	return
+}
+
+//ReleaseHistory retries helm.Interface.ReleaseHistory, which is idempotent
+func (c *retryingClient) ReleaseHistory(rlsName string,
+	opts ...helm.HistoryOption) (resp *rls.GetHistoryResponse, err error) {
+	err = c.policy.Do("get history of "+rlsName, func() (err error) {
+		resp, err = c.Interface.ReleaseHistory(rlsName, opts...)
+		return
+	})
+	return
+}
+
+//ReleaseContent retries helm.Interface.ReleaseContent, which is idempotent
+func (c *retryingClient) ReleaseContent(rlsName string,
+	opts ...helm.ContentOption) (resp *rls.GetReleaseContentResponse, err error) {
+	err = c.policy.Do("get content of "+rlsName, func() (err error) {
+		resp, err = c.Interface.ReleaseContent(rlsName, opts...)
+		return
+	})
+	return
+}
@@ -0,0 +1,262 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Endpoint  = "https://s3.amazonaws.com"
+	defaultS3Region    = "us-east-1"
+	gcsEndpoint        = "https://storage.googleapis.com"
+	gcsRegion          = "auto"
+	amzDateFormat      = "20060102T150405Z"
+	amzShortDateFormat = "20060102"
+)
+
+//s3Store is a Store backed by a bucket in an S3-compatible object store.
+//Objects are addressed path-style, i.e. endpoint/bucket/key, and requests are
+//signed with AWS Signature Version 4 when credentials are available.
+type s3Store struct {
+	endpoint     string
+	region       string
+	bucket       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+//newS3Store returns an s3Store for the provided bucket, configured from the
+//standard AWS environment variables. HELM_BULK_S3_ENDPOINT can be set to use
+//an S3-compatible service other than AWS, e.g. Minio or Ceph.
+func newS3Store(bucket string) *s3Store {
+	return &s3Store{
+		endpoint:     envOrDefault("HELM_BULK_S3_ENDPOINT", defaultS3Endpoint),
+		region:       envOrDefault("AWS_REGION", defaultS3Region),
+		bucket:       bucket,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       http.DefaultClient,
+	}
+}
+
+//newGCSStore returns an s3Store for the provided Google Cloud Storage bucket,
+//using the GCS XML API's S3 interoperability and HMAC keys obtained from
+//HELM_BULK_GCS_ACCESS_KEY_ID and HELM_BULK_GCS_SECRET_ACCESS_KEY
+func newGCSStore(bucket string) *s3Store {
+	return &s3Store{
+		endpoint:  gcsEndpoint,
+		region:    gcsRegion,
+		bucket:    bucket,
+		accessKey: os.Getenv("HELM_BULK_GCS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("HELM_BULK_GCS_SECRET_ACCESS_KEY"),
+		client:    http.DefaultClient,
+	}
+}
+
+//Get downloads the named object from the bucket
+func (s *s3Store) Get(name string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+//Put uploads the contents of the Reader to the named object in the bucket
+func (s *s3Store) Put(name string, r io.Reader) error {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(http.MethodPut, name, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+//listBucketResult is the subset of an S3 ListObjectsV2 response that's used
+type listBucketResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+//List returns the keys of objects in the bucket beginning with prefix,
+//following continuation tokens until the listing is complete
+func (s *s3Store) List(prefix string) (names []string, err error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		var result listBucketResult
+		if err = s.getXML(query, &result); err != nil {
+			return nil, err
+		}
+		for _, object := range result.Contents {
+			names = append(names, object.Key)
+		}
+		if !result.IsTruncated {
+			return
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+//getXML lists the bucket with the provided query, decoding the XML response
+func (s *s3Store) getXML(query url.Values, v interface{}) error {
+	resp, err := s.do(http.MethodGet, "?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+//Delete removes the named object from the bucket
+func (s *s3Store) Delete(name string) error {
+	resp, err := s.do(http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+//do sends a signed request for the named object, returning an error for any
+//non-2xx response, which is a NotFoundError for a 404
+func (s *s3Store) do(method, name string, body []byte) (*http.Response, error) {
+	target := s.objectURL(name)
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, body, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, NotFoundError{Name: target}
+	}
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, target, resp.Status)
+	}
+	return resp, nil
+}
+
+//objectURL returns the path-style URL of the named object, or of the bucket
+//itself when name is only a query string
+func (s *s3Store) objectURL(name string) string {
+	bucketURL := strings.TrimSuffix(s.endpoint, "/") + "/" + s.bucket
+	if strings.HasPrefix(name, "?") {
+		return bucketURL + name
+	}
+	return bucketURL + "/" + name
+}
+
+//sign adds AWS Signature Version 4 headers to the request. Requests are left
+//unsigned if no credentials are configured, which allows public buckets.
+func (s *s3Store) sign(req *http.Request, body []byte, now time.Time) {
+	if s.accessKey == "" || s.secretKey == "" {
+		return
+	}
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.Replace(req.URL.Query().Encode(), "+", "%20", -1),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := strings.Join([]string{now.Format(amzShortDateFormat), s.region,
+		"s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256",
+		now.Format(amzDateFormat), scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format(amzShortDateFormat))
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+//canonicalHeaders returns the SigV4 signed header list and canonical header
+//block for the request's host and x-amz-* headers
+func canonicalHeaders(req *http.Request) (signed, canonical string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buffer bytes.Buffer
+	for _, name := range names {
+		buffer.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return strings.Join(names, ";"), buffer.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+//envOrDefault returns the value of the environment variable, or the provided
+//default if it's unset
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
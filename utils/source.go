@@ -0,0 +1,80 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+//SavedMetadata returns the metadata of an archive of Releases saved now, by the
+//current user, from the Tiller version in the Cluster of the context. The
+//Tiller namespace is taken from TILLER_NAMESPACE, which helm sets for plugins
+//to the namespace it used, and left unrecorded if it isn't set.
+func SavedMetadata(helmBulkVersion, tillerVersion string,
+	context ClusterContext) *ArchiveMetadata {
+	return &ArchiveMetadata{
+		HelmBulkVersion: helmBulkVersion,
+		TillerVersion:   tillerVersion,
+		KubeContext:     context.Name,
+		Server:          context.Server,
+		TillerNamespace: os.Getenv("TILLER_NAMESPACE"),
+		User:            currentUser(),
+		SavedAt:         time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+//currentUser returns the name of the local user, or empty if it isn't known
+func currentUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+//MergedMetadata returns the metadata of an archive merging Releases with the
+//metadata of the first and second archives. It's the first's, without the
+//source Cluster and Tiller namespace unless both archives record the same ones.
+func MergedMetadata(first, second *ArchiveMetadata) *ArchiveMetadata {
+	if first == nil {
+		return nil
+	}
+	merged := *first
+	if second == nil || !sameServer(first.Server, second.Server) ||
+		first.TillerNamespace != second.TillerNamespace {
+		merged.KubeContext = ""
+		merged.Server = ""
+		merged.TillerNamespace = ""
+	}
+	return &merged
+}
+
+//CrossCluster returns whether Releases with the metadata were saved from a
+//Cluster other than the context's, which is false if either server isn't known,
+//e.g. for imported archives
+func CrossCluster(metadata *ArchiveMetadata, context ClusterContext) bool {
+	if metadata == nil || metadata.Server == "" || context.Server == "" {
+		return false
+	}
+	return !sameServer(metadata.Server, context.Server)
+}
+
+//sameServer returns whether the API server URLs are the same, ignoring any
+//trailing slash
+func sameServer(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
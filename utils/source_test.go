@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSavedMetadata(t *testing.T) {
+	context := ClusterContext{Name: "staging", Server: "https://staging:6443"}
+	metadata := SavedMetadata("0.0.28", "v2.13.1", context)
+	if metadata.KubeContext != "staging" ||
+		metadata.Server != "https://staging:6443" ||
+		metadata.TillerVersion != "v2.13.1" {
+		t.Errorf("Incorrect metadata, got: %+v.", metadata)
+	}
+	if _, err := time.Parse(time.RFC3339, metadata.SavedAt); err != nil {
+		t.Errorf("Saved time isn't RFC 3339, got: %s.", metadata.SavedAt)
+	}
+}
+
+func TestMergedMetadata(t *testing.T) {
+	staging := &ArchiveMetadata{KubeContext: "staging",
+		Server: "https://staging:6443", TillerNamespace: "kube-system",
+		TillerVersion: "v2.13.1"}
+	prod := &ArchiveMetadata{KubeContext: "prod", Server: "https://prod:6443",
+		TillerNamespace: "kube-system"}
+	if merged := MergedMetadata(staging, staging); *merged != *staging {
+		t.Errorf("Incorrect metadata for the same source, got: %+v.", merged)
+	}
+	for _, second := range []*ArchiveMetadata{prod, {}, nil} {
+		merged := MergedMetadata(staging, second)
+		if merged.Server != "" || merged.KubeContext != "" ||
+			merged.TillerNamespace != "" || merged.TillerVersion != "v2.13.1" {
+			t.Errorf("Incorrect metadata merging %+v, got: %+v.", second, merged)
+		}
+	}
+	if MergedMetadata(nil, staging) != nil {
+		t.Error("Metadata merged into an archive without it")
+	}
+}
+
+func TestCrossCluster(t *testing.T) {
+	metadata := &ArchiveMetadata{Server: "https://staging:6443"}
+	for _, c := range []struct {
+		metadata *ArchiveMetadata
+		server   string
+		cross    bool
+	}{
+		{metadata, "https://staging:6443/", false},
+		{metadata, "https://prod:6443", true},
+		{metadata, "", false},
+		{&ArchiveMetadata{}, "https://prod:6443", false},
+		{nil, "https://prod:6443", false},
+	} {
+		context := ClusterContext{Name: "prod", Server: c.server}
+		if cross := CrossCluster(c.metadata, context); cross != c.cross {
+			t.Errorf("Incorrect cross cluster for %+v and %s, got: %t, want: %t.",
+				c.metadata, c.server, cross, c.cross)
+		}
+	}
+}
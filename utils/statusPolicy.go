@@ -0,0 +1,108 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+//Actions taken on load for a saved Release that's already installed, depending
+//on the installed Release's status
+const (
+	//StatusActionExisting treats the Release as installed; it's upgraded with
+	//--upgrade, purged and reinstalled with --delete, and otherwise left alone
+	StatusActionExisting = "existing"
+	//StatusActionUpgrade upgrades the Release, even without --upgrade
+	StatusActionUpgrade = "upgrade"
+	//StatusActionReinstall installs the Release, reusing its name
+	StatusActionReinstall = "reinstall"
+	//StatusActionSkip leaves the Release alone, with a warning
+	StatusActionSkip = "skip"
+)
+
+//StatusPolicy maps the status of an installed Release to the action to take
+//when loading a saved Release with the same identity
+type StatusPolicy map[release.Status_Code]string
+
+//DefaultStatusPolicy returns the StatusPolicy used for statuses without an
+//override
+func DefaultStatusPolicy() StatusPolicy {
+	return StatusPolicy{
+		release.Status_UNKNOWN:          StatusActionSkip,
+		release.Status_DEPLOYED:         StatusActionExisting,
+		release.Status_DELETED:          StatusActionReinstall,
+		release.Status_FAILED:           StatusActionUpgrade,
+		release.Status_DELETING:         StatusActionSkip,
+		release.Status_PENDING_INSTALL:  StatusActionSkip,
+		release.Status_PENDING_UPGRADE:  StatusActionSkip,
+		release.Status_PENDING_ROLLBACK: StatusActionSkip,
+	}
+}
+
+//NewStatusPolicy returns the default StatusPolicy with the provided overrides
+//applied, which map status names (e.g. FAILED) to actions
+func NewStatusPolicy(overrides map[string]string) (StatusPolicy, error) {
+	policy := DefaultStatusPolicy()
+	for name, action := range overrides {
+		code, ok := release.Status_Code_value[strings.ToUpper(name)]
+		if !ok || release.Status_Code(code) == release.Status_SUPERSEDED {
+			return nil, fmt.Errorf("unknown Release status in status-policy: %s", name)
+		}
+		switch action {
+		case StatusActionExisting, StatusActionUpgrade, StatusActionReinstall,
+			StatusActionSkip:
+			policy[release.Status_Code(code)] = action
+		default:
+			return nil, fmt.Errorf("unknown action for status %s in status-policy: %s",
+				name, action)
+		}
+	}
+	return policy, nil
+}
+
+//Statuses returns the statuses the policy covers, which are those of Releases
+//to list when comparing against saved Releases
+func (p StatusPolicy) Statuses() (codes []release.Status_Code) {
+	for code := range p {
+		codes = append(codes, code)
+	}
+	return
+}
+
+//Action returns the action to take for an installed Release
+func (p StatusPolicy) Action(installed *release.Release) string {
+	return p[installed.GetInfo().GetStatus().GetCode()]
+}
+
+//LatestRevisions returns only the latest revision of each named Release in the
+//provided slice, preserving order
+func LatestRevisions(releases []*release.Release) (latest []*release.Release) {
+	newest := map[string]*release.Release{}
+	for _, release := range releases {
+		if current, ok := newest[release.GetName()]; !ok ||
+			release.GetVersion() > current.GetVersion() {
+			newest[release.GetName()] = release
+		}
+	}
+	for _, release := range releases {
+		if newest[release.GetName()] == release {
+			latest = append(latest, release)
+		}
+	}
+	return
+}
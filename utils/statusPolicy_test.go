@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestNewStatusPolicy(t *testing.T) {
+	policy, err := NewStatusPolicy(map[string]string{"failed": StatusActionSkip})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	failed := &release.Release{Info: &release.Info{
+		Status: &release.Status{Code: release.Status_FAILED}}}
+	if action := policy.Action(failed); action != StatusActionSkip {
+		t.Errorf("Incorrect action returned, got: %s, want: %s.", action,
+			StatusActionSkip)
+	}
+	deleted := &release.Release{Info: &release.Info{
+		Status: &release.Status{Code: release.Status_DELETED}}}
+	if action := policy.Action(deleted); action != StatusActionReinstall {
+		t.Errorf("Incorrect action returned, got: %s, want: %s.", action,
+			StatusActionReinstall)
+	}
+	for _, overrides := range []map[string]string{
+		{"BROKEN": StatusActionSkip},
+		{"SUPERSEDED": StatusActionSkip},
+		{"FAILED": "ignore"},
+	} {
+		if _, err := NewStatusPolicy(overrides); err == nil {
+			t.Errorf("No error returned for status-policy: %v", overrides)
+		}
+	}
+}
+
+func TestLatestRevisions(t *testing.T) {
+	releases := []*release.Release{
+		{Name: "app", Version: 1},
+		{Name: "db", Version: 4},
+		{Name: "app", Version: 3},
+		{Name: "app", Version: 2},
+	}
+	latest := LatestRevisions(releases)
+	if len(latest) != 2 || latest[0].GetName() != "db" ||
+		latest[1].GetVersion() != 3 {
+		t.Errorf("Incorrect Releases returned, got: %v.", latest)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//StdioLocation is the archive location that reads from stdin and writes to
+//stdout
+const StdioLocation = "-"
+
+//Store reads, writes, lists and deletes archives by name, e.g. in a local
+//directory, an S3 bucket or under an HTTP endpoint
+type Store interface {
+	Get(name string) (io.ReadCloser, error)
+	Put(name string, r io.Reader) error
+	List(prefix string) ([]string, error)
+	Delete(name string) error
+}
+
+//NotFoundError is returned by a Store's Get when the named archive doesn't
+//exist
+type NotFoundError struct {
+	Name string
+}
+
+func (e NotFoundError) Error() string {
+	return "archive not found: " + e.Name
+}
+
+//IsNotFound returns whether the error is a NotFoundError
+func IsNotFound(err error) bool {
+	_, ok := err.(NotFoundError)
+	return ok
+}
+
+//NewStore returns the Store that the provided location resolves to, along with
+//the name of the archive within that Store. Locations can be local paths, or
+//URLs with an s3://, gs://, http:// or https:// scheme. The location "-"
+//streams the archive through stdin/stdout.
+func NewStore(location string) (store Store, name string, err error) {
+	if location == StdioLocation {
+		return stdioStore{}, StdioLocation, nil
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return localStore{dir: filepath.Dir(location)}, filepath.Base(location), nil
+	}
+	switch u.Scheme {
+	case "s3":
+		store = newS3Store(u.Host)
+	case "gs":
+		store = newGCSStore(u.Host)
+	case "http", "https":
+		dir, base := path.Split(u.Path)
+		u.Path = dir
+		return httpStore{baseURL: u.String()}, base, nil
+	default:
+		return localStore{dir: filepath.Dir(location)}, filepath.Base(location), nil
+	}
+	name = strings.TrimPrefix(u.Path, "/")
+	return
+}
+
+//LocalPath returns the filesystem path of the named file in the Store, and
+//false if the Store isn't a local directory
+func LocalPath(store Store, name string) (path string, ok bool) {
+	local, ok := store.(localStore)
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(local.dir, name), true
+}
+
+//localStore is a Store backed by a directory on the local filesystem
+type localStore struct {
+	dir string
+}
+
+//Get opens the named file in the Store's directory
+func (s localStore) Get(name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil, NotFoundError{Name: filepath.Join(s.dir, name)}
+	}
+	return f, err
+}
+
+//Put writes to a temporary file in the Store's directory and renames it once
+//complete, so a failed write never leaves a truncated archive behind
+func (s localStore) Put(name string, r io.Reader) error {
+	tmp, err := ioutil.TempFile(s.dir, "."+name+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
+
+//List returns the names of files in the Store's directory beginning with prefix
+func (s localStore) List(prefix string) (names []string, err error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), prefix) {
+			names = append(names, file.Name())
+		}
+	}
+	return
+}
+
+//Delete removes the named file from the Store's directory
+func (s localStore) Delete(name string) error {
+	return os.Remove(filepath.Join(s.dir, name))
+}
+
+//stdioStore is a Store that reads archives from stdin and writes them to
+//stdout, ignoring names
+type stdioStore struct{}
+
+//Get returns stdin
+func (stdioStore) Get(name string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(os.Stdin), nil
+}
+
+//Put copies the contents of the Reader to stdout
+func (stdioStore) Put(name string, r io.Reader) error {
+	_, err := io.Copy(os.Stdout, r)
+	return err
+}
+
+//List isn't supported when streaming
+func (stdioStore) List(prefix string) ([]string, error) {
+	return nil, errors.New("listing archives is not supported with stdin/stdout")
+}
+
+//Delete isn't supported when streaming
+func (stdioStore) Delete(name string) error {
+	return errors.New("deleting archives is not supported with stdin/stdout")
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeObjectServer is an in-process stand-in for an S3-compatible or plain HTTP
+// object store, keeping objects in memory keyed by request path
+type fakeObjectServer struct {
+	sync.Mutex
+	objects       map[string][]byte
+	authorization string
+}
+
+func newFakeObjectServer() (*fakeObjectServer, *httptest.Server) {
+	fake := &fakeObjectServer{objects: map[string][]byte{}}
+	return fake, httptest.NewServer(fake)
+}
+
+func (f *fakeObjectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	f.authorization = r.Header.Get("Authorization")
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") == "2" {
+			f.list(w, r)
+			return
+		}
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(body)
+	}
+}
+
+//list writes an S3 ListObjectsV2 response for the bucket in the request path
+func (f *fakeObjectServer) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Path + "/" + r.URL.Query().Get("prefix")
+	fmt.Fprint(w, "<ListBucketResult>")
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>",
+				strings.TrimPrefix(key, r.URL.Path+"/"))
+		}
+	}
+	fmt.Fprint(w, "</ListBucketResult>")
+}
+
+func assertRoundTrip(t *testing.T, store Store, name string) {
+	if err := store.Put(name, strings.NewReader("archive")); err != nil {
+		t.Fatal("Error putting archive", err)
+	}
+	rc, err := store.Get(name)
+	if err != nil {
+		t.Fatal("Error getting archive", err)
+	}
+	defer rc.Close()
+	actual, _ := ioutil.ReadAll(rc)
+	if !bytes.Equal(actual, []byte("archive")) {
+		t.Errorf("Archive contents were incorrect, got: %s, want: %s.",
+			actual, "archive")
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	for location, expectedName := range map[string]string{
+		"helm-releases.tar.gz":                "helm-releases.tar.gz",
+		"backups/helm-releases.tar.gz":        "helm-releases.tar.gz",
+		"s3://bucket/backups/helm.tar.gz":     "backups/helm.tar.gz",
+		"gs://bucket/helm.tar.gz":             "helm.tar.gz",
+		"https://example.com/a/b/helm.tar.gz": "helm.tar.gz",
+	} {
+		_, name, err := NewStore(location)
+		if err != nil || name != expectedName {
+			t.Errorf("Archive name was incorrect for %s, got: %s, want: %s.",
+				location, name, expectedName)
+		}
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "helm-bulk")
+	defer os.RemoveAll(dir)
+	assertRoundTrip(t, localStore{dir: dir}, "helm-releases.tar.gz")
+	if _, err := (localStore{dir: dir}).Get("missing.tar.gz"); !IsNotFound(err) {
+		t.Errorf("Expected a NotFoundError getting a missing archive, got: %v",
+			err)
+	}
+}
+
+func TestLocalStoreConcurrentPuts(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "helm-bulk")
+	defer os.RemoveAll(dir)
+	store := localStore{dir: dir}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.Put("helm-releases.tar.gz",
+				strings.NewReader("archive")); err != nil {
+				t.Error("Error putting archive", err)
+			}
+		}()
+	}
+	wg.Wait()
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Temporary files were left behind, got: %d files, want: 1.",
+			len(files))
+	}
+	assertRoundTrip(t, store, "helm-releases.tar.gz")
+}
+
+func TestS3Store(t *testing.T) {
+	fake, server := newFakeObjectServer()
+	defer server.Close()
+	store := &s3Store{endpoint: server.URL, region: defaultS3Region,
+		bucket: "bucket", accessKey: "key", secretKey: "secret",
+		client: server.Client()}
+	assertRoundTrip(t, store, "backups/helm-releases.tar.gz")
+	if _, ok := fake.objects["/bucket/backups/helm-releases.tar.gz"]; !ok {
+		t.Error("Archive was not stored at the path-style bucket/key")
+	}
+	if !strings.HasPrefix(fake.authorization, "AWS4-HMAC-SHA256 Credential=key/") {
+		t.Errorf("Request was not signed, got Authorization: %s",
+			fake.authorization)
+	}
+	store.Put("backups/other.tar.gz", strings.NewReader("other"))
+	names, err := store.List("backups/helm-")
+	if err != nil || len(names) != 1 || names[0] != "backups/helm-releases.tar.gz" {
+		t.Errorf("Listed keys were incorrect, got: %v, want: %v.", names,
+			[]string{"backups/helm-releases.tar.gz"})
+	}
+	store.Delete("backups/helm-releases.tar.gz")
+	if _, err := store.Get("backups/helm-releases.tar.gz"); err == nil {
+		t.Error("Expected an error getting a deleted archive")
+	}
+}
+
+func TestHTTPStore(t *testing.T) {
+	fake, server := newFakeObjectServer()
+	defer server.Close()
+	store, name, _ := NewStore(server.URL + "/backups/helm-releases.tar.gz")
+	assertRoundTrip(t, store, name)
+	if _, ok := fake.objects["/backups/helm-releases.tar.gz"]; !ok {
+		t.Error("Archive was not stored at the URL path")
+	}
+	if _, err := store.Get("missing.tar.gz"); !IsNotFound(err) {
+		t.Errorf("Expected a NotFoundError getting a missing archive, got: %v",
+			err)
+	}
+}
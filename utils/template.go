@@ -0,0 +1,139 @@
+// Copyright 2018 OVO Technology
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/renderutil"
+)
+
+//hookAnnotation marks a resource in a chart as a hook, rather than part of the
+//Release's manifest
+const hookAnnotation = "helm.sh/hook"
+
+//installOrder is the order Tiller installs resources in, by kind, and so the
+//order of a Release's manifest. Other kinds come last.
+var installOrder = []string{
+	"Namespace", "ResourceQuota", "LimitRange", "PodSecurityPolicy",
+	"PodDisruptionBudget", "Secret", "ConfigMap", "StorageClass",
+	"PersistentVolume", "PersistentVolumeClaim", "ServiceAccount",
+	"CustomResourceDefinition", "ClusterRole", "ClusterRoleBinding", "Role",
+	"RoleBinding", "Service", "DaemonSet", "Pod", "ReplicationController",
+	"ReplicaSet", "Deployment", "StatefulSet", "Job", "CronJob", "Ingress",
+	"APIService",
+}
+
+//RenderRelease renders the Release's saved chart offline, with its saved
+//values merged with the overrides, and returns the manifest as Tiller would
+//store it, i.e. without hooks or NOTES.txt. The Kubernetes version reported to
+//templates can be set with kubeVersion, e.g. 1.14.
+func RenderRelease(rel *release.Release, overrides string,
+	kubeVersion string) (string, error) {
+	config, err := renderConfig(rel.GetConfig().GetRaw(), overrides)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := renderutil.Render(rel.GetChart(), config,
+		renderutil.Options{
+			ReleaseOptions: chartutil.ReleaseOptions{
+				Name:      rel.GetName(),
+				Namespace: rel.GetNamespace(),
+				Revision:  int(rel.GetVersion()),
+				IsInstall: rel.GetVersion() <= 1,
+				IsUpgrade: rel.GetVersion() > 1,
+				Time:      rel.GetInfo().GetLastDeployed(),
+			},
+			KubeVersion: kubeVersion,
+		})
+	if err != nil {
+		return "", err
+	}
+	resources, err := renderedResources(rendered)
+	if err != nil {
+		return "", err
+	}
+	var manifest bytes.Buffer
+	for _, resource := range resources {
+		manifest.WriteString("---\n" + resource.Manifest + "\n")
+	}
+	return manifest.String(), nil
+}
+
+//renderConfig returns the saved values merged with the overrides, as a chart
+//Config that's merged with the chart's defaults on render
+func renderConfig(saved, overrides string) (*chart.Config, error) {
+	values, err := chartutil.ReadValues([]byte(saved))
+	if err != nil {
+		return nil, err
+	}
+	overrideValues, err := chartutil.ReadValues([]byte(overrides))
+	if err != nil {
+		return nil, err
+	}
+	mergeValues(values, overrideValues)
+	raw, err := values.YAML()
+	return &chart.Config{Raw: raw}, err
+}
+
+//renderedResources returns the resources in the rendered templates, sorted
+//into install order, skipping hooks and NOTES.txt. Each resource's manifest is
+//commented with its source template, as in a Release's manifest.
+func renderedResources(rendered map[string]string) (resources []Resource,
+	err error) {
+	var paths []string
+	for path := range rendered {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if strings.HasSuffix(path, "NOTES.txt") {
+			continue
+		}
+		for _, doc := range splitManifest(rendered[path]) {
+			var resource Resource
+			if err = yaml.Unmarshal([]byte(doc), &resource); err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			if resource.Kind == "" ||
+				resource.Metadata.Annotations[hookAnnotation] != "" {
+				continue
+			}
+			resource.Manifest = "# Source: " + path + "\n" + doc
+			resources = append(resources, resource)
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		return kindRank(resources[i].Kind) < kindRank(resources[j].Kind)
+	})
+	return
+}
+
+//kindRank returns the position of the kind in the install order
+func kindRank(kind string) int {
+	for i, ordered := range installOrder {
+		if kind == ordered {
+			return i
+		}
+	}
+	return len(installOrder)
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func testChartRelease() *release.Release {
+	return &release.Release{
+		Name:      "app",
+		Namespace: "web",
+		Version:   1,
+		Config:    &chart.Config{Raw: "replicas: 2\n"},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: "app", Version: "0.1.0"},
+			Values:   &chart.Config{Raw: "replicas: 1\nport: 80\n"},
+			Templates: []*chart.Template{
+				{Name: "templates/a-deploy.yaml", Data: []byte(
+					"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n" +
+						"  name: {{ .Release.Name }}\nspec:\n" +
+						"  replicas: {{ .Values.replicas }}\n")},
+				{Name: "templates/b-svc.yaml", Data: []byte(
+					"apiVersion: v1\nkind: Service\nmetadata:\n" +
+						"  name: {{ .Release.Name }}\nspec:\n" +
+						"  port: {{ .Values.port }}\n")},
+				{Name: "templates/test.yaml", Data: []byte(
+					"apiVersion: v1\nkind: Pod\nmetadata:\n  name: test\n" +
+						"  annotations:\n    helm.sh/hook: test-success\n")},
+				{Name: "templates/NOTES.txt", Data: []byte("Installed!")},
+			},
+		},
+	}
+}
+
+func TestRenderRelease(t *testing.T) {
+	manifest, err := RenderRelease(testChartRelease(), "port: 8080\n", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resources, err := ManifestResources(manifest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resources) != 2 || resources[0].Kind != "Service" ||
+		resources[1].Kind != "Deployment" {
+		t.Errorf("Incorrect resources rendered, got: %s", manifest)
+	}
+	for _, expected := range []string{"replicas: 2", "port: 8080",
+		"# Source: app/templates/b-svc.yaml"} {
+		if !strings.Contains(manifest, expected) {
+			t.Errorf("Manifest is missing %s, got: %s", expected, manifest)
+		}
+	}
+	if strings.Contains(manifest, "Installed!") {
+		t.Errorf("NOTES.txt was rendered into the manifest, got: %s", manifest)
+	}
+}
+
+func TestManifestDiff(t *testing.T) {
+	saved, _ := RenderRelease(testChartRelease(), "", "")
+	fresh, _ := RenderRelease(testChartRelease(), "", "")
+	if diff, err := ManifestDiff(saved, fresh); err != nil || diff != "" {
+		t.Errorf("Identical renders differ, got: %s, %v.", diff, err)
+	}
+	fresh, _ = RenderRelease(testChartRelease(), "replicas: 3\n", "")
+	diff, err := ManifestDiff(saved, fresh)
+	if err != nil || !strings.Contains(diff, "=== Deployment/app\n") ||
+		!strings.Contains(diff, "\n-  replicas: 2\n+  replicas: 3\n") ||
+		strings.Contains(diff, "Service/app") {
+		t.Errorf("Incorrect diff, got: %s, %v.", diff, err)
+	}
+	diff, _ = ManifestDiff("", fresh)
+	if strings.Count(diff, "===") != 2 || strings.Contains(diff, "\n-") {
+		t.Errorf("Incorrect diff against an empty manifest, got: %s", diff)
+	}
+}